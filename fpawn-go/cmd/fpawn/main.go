@@ -4,12 +4,18 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"strings"
 	"time"
 
 	"github.com/FerzDevZ/fpawn/internal/analysis"
 	"github.com/FerzDevZ/fpawn/internal/compiler"
 	"github.com/FerzDevZ/fpawn/internal/core"
 	"github.com/FerzDevZ/fpawn/internal/plugins"
+	"github.com/FerzDevZ/fpawn/internal/rcon"
+	"github.com/FerzDevZ/fpawn/internal/report"
+	"github.com/FerzDevZ/fpawn/internal/server"
+	"github.com/FerzDevZ/fpawn/internal/servercfg"
+	"github.com/FerzDevZ/fpawn/internal/toolchain"
 	"github.com/FerzDevZ/fpawn/internal/tools"
 	"github.com/FerzDevZ/fpawn/internal/ui"
 )
@@ -26,8 +32,20 @@ func main() {
 	// Security Check
 	core.CheckLicense()
 
+	// Strip global flags (--json, --format) before dispatching
+	args, err := parseGlobalFlags(os.Args[1:])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(2)
+	}
+	os.Args = append(os.Args[:1], args...)
+	report.Version = version
+
 	// If no arguments, show professional launcher
 	if len(os.Args) < 2 {
+		if report.Machine() {
+			report.Fail("", fmt.Errorf("a command is required with --format=%s", report.Current), 2)
+		}
 		ui.ShowSplash()
 		ui.ShowDashboard() // Direct transition to Go Power Suite
 		return
@@ -36,35 +54,56 @@ func main() {
 	// CLI mode
 	arg := os.Args[1]
 
+	if report.Machine() && !machineCommands[arg] && !strings.HasSuffix(arg, ".pwn") {
+		report.Fail(arg, fmt.Errorf("command does not support --format=%s", report.Current), 2)
+	}
+
 	switch arg {
 	case "--help", "-h":
 		showHelp()
 
 	case "--version", "-v":
+		if report.Machine() {
+			emit(arg, map[string]string{"version": version, "license": core.CurrentLicense.Serial})
+			return
+		}
 		fmt.Printf("FerzDevZ FPAWN v%s - PRO EDITION\n", version)
 		fmt.Println("Proprietary Software by FerzDevZ")
 		fmt.Printf("License: %s (Active)\n", core.CurrentLicense.Serial)
 
 	case "--compile", "-c":
-		target := getArg(2)
-		result := compiler.Compile(target, compiler.ProfileAuto)
-		if !result.Success {
-			os.Exit(1)
-		}
+		profile := takeFlag("--profile")
+		runCompile(arg, getArg(2), compiler.Profile(profile))
 
 	case "--watch", "-w":
 		target := getArg(2)
-		if err := compiler.WatchMode(target); err != nil {
+		watch := ui.WatchDashboard
+		if !isTerminal(os.Stdout) {
+			watch = compiler.WatchMode
+		}
+		if err := watch(target); err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
 
 	case "--doctor":
 		target := getArg(2)
+		if report.Machine() {
+			emit(arg, analysis.Diagnose(target))
+			return
+		}
 		analysis.ProjectDoctor(target)
 
 	case "--audit":
 		target := getArg(2)
+		if report.Machine() {
+			result, err := analysis.AuditSource(target)
+			if err != nil {
+				report.Fail(arg, err, 1)
+			}
+			emit(arg, result)
+			return
+		}
 		analysis.SecurityAudit(target)
 
 	case "--guard":
@@ -81,7 +120,7 @@ func main() {
 		}
 
 	case "--run":
-		if err := compiler.RunServer(); err != nil {
+		if err := server.RestartServer(); err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
@@ -97,34 +136,85 @@ func main() {
 	// === NEW FEATURES ===
 
 	case "--install", "-i":
-		name := getArg(2)
-		if name == "" {
+		if getArg(2) == "" {
+			if report.Machine() {
+				emit(arg, plugins.Catalogue())
+				return
+			}
 			plugins.ListPlugins()
 		} else {
-			if err := plugins.InstallPlugin(name); err != nil {
-				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-				os.Exit(1)
+			runInstall(arg)
+		}
+
+	case "install":
+		runInstall(arg)
+
+	case "registry":
+		runRegistry(arg)
+
+	case "plugins":
+		runPlugins(arg)
+
+	case "outdated":
+		client := plugins.NewClient(takeFlag("--source"))
+		if report.Machine() {
+			result, err := plugins.CheckOutdated(client)
+			if err != nil {
+				report.Fail(arg, err, 1)
 			}
+			emit(arg, result)
+			return
+		}
+		if _, err := plugins.ShowOutdated(client); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+	case "upgrade":
+		client := plugins.NewClient(takeFlag("--source"))
+		tag := takeFlag("--tag")
+		name := getArg(2)
+		if name == "" {
+			fmt.Println("Usage: fpawn upgrade <plugin-name> [--tag <release>]")
+			os.Exit(1)
+		}
+		if err := plugins.UpgradePlugin(name, tag, client); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
 		}
 
 	case "--uninstall":
+		force := takeSwitch("--force")
 		name := getArg(2)
 		if name == "" {
-			fmt.Println("Usage: fpawn --uninstall <plugin-name>")
-		} else {
-			if err := plugins.UninstallPlugin(name); err != nil {
-				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-				os.Exit(1)
+			fmt.Println("Usage: fpawn --uninstall <plugin-name> [--force]")
+			os.Exit(1)
+		}
+		if report.Machine() {
+			result, err := plugins.RemovePlugin(name, force)
+			if err != nil {
+				report.Fail(arg, err, 1)
 			}
+			emit(arg, result)
+			return
+		}
+		if _, err := plugins.UninstallPlugin(name, force); err != nil {
+			os.Exit(1)
 		}
 
 	case "--plugins":
+		if report.Machine() {
+			emit(arg, plugins.Catalogue())
+			return
+		}
 		plugins.ListPlugins()
 
 	case "--search":
 		query := getArg(2)
 		if query == "" {
 			fmt.Println("Usage: fpawn --search <query>")
+		} else if report.Machine() {
+			emit(arg, plugins.SearchPlugins(query))
 		} else {
 			results := plugins.SearchPlugins(query)
 			fmt.Printf("\n Found %d plugins:\n", len(results))
@@ -134,10 +224,40 @@ func main() {
 		}
 
 	case "--verify":
-		plugins.VerifyPlugins()
+		strict := takeSwitch("--strict")
+		if report.Machine() {
+			result, err := plugins.ScanPlugins()
+			if err != nil {
+				report.Fail(arg, err, 1)
+			}
+			emit(arg, result)
+			if result.Tampered(strict) {
+				os.Exit(1)
+			}
+			return
+		}
+		result, err := plugins.VerifyPlugins()
+		if err != nil || result.Tampered(strict) {
+			os.Exit(1)
+		}
 
 	case "--deps":
-		plugins.CheckDependencies()
+		target := getArg(2)
+		if report.Machine() {
+			result, err := plugins.AnalyzeDependencies(target)
+			if err != nil {
+				report.Fail(arg, err, 1)
+			}
+			emit(arg, result)
+			if result.Failed() {
+				os.Exit(1)
+			}
+			return
+		}
+		result, err := plugins.CheckDependencies(target)
+		if err != nil || result.Failed() {
+			os.Exit(1)
+		}
 
 	case "--artisan":
 		target := getArg(2)
@@ -148,6 +268,14 @@ func main() {
 
 	case "--lint":
 		target := getArg(2)
+		if report.Machine() {
+			result, err := tools.LintFile(target)
+			if err != nil {
+				report.Fail(arg, err, 1)
+			}
+			emit(arg, result)
+			return
+		}
 		tools.Linter(target)
 
 	case "--sandbox":
@@ -162,6 +290,10 @@ func main() {
 
 	case "--analytics":
 		target := getArg(2)
+		if report.Machine() {
+			emit(arg, analysis.AnalyzePerformance(target))
+			return
+		}
 		analysis.PerformanceAnalytics(target)
 
 	case "--scan":
@@ -178,12 +310,107 @@ func main() {
 		}
 
 	case "--bench":
+		opts := compiler.BenchOptions{
+			Iterations: core.ToInt(takeFlag("--iterations")),
+			Warmup:     1,
+			Profile:    compiler.Profile(takeFlag("--profile")),
+		}
+		if w := takeFlag("--warmup"); w != "" {
+			opts.Warmup = core.ToInt(w)
+		}
 		target := getArg(2)
-		compiler.Benchmark(target, 5)
+		if report.Machine() {
+			result, err := compiler.RunBenchmark(target, opts, nil)
+			if err != nil {
+				report.Fail(arg, err, 1)
+			}
+			emit(arg, result)
+			return
+		}
+		compiler.BenchmarkWithOptions(target, opts)
 
 	case "--matrix":
+		jobs := core.ToInt(takeFlag("--jobs"))
 		target := getArg(2)
-		compiler.HybridMatrixBuild(target)
+		var result *compiler.MatrixResult
+		if report.Machine() {
+			result = compiler.RunMatrix(target, jobs, nil)
+			emit(arg, result)
+		} else {
+			result = compiler.HybridMatrixBuild(target, jobs)
+		}
+		for _, e := range result.Entries {
+			if !e.Result.Success {
+				os.Exit(1)
+			}
+		}
+
+	case "--build-all":
+		jobs := core.ToInt(takeFlag("--jobs"))
+		profile := compiler.Profile(takeFlag("--profile"))
+		if profile == "" {
+			profile = compiler.ProfileAuto
+		}
+		var result *compiler.BuildAllResult
+		if report.Machine() {
+			result = compiler.RunBuildAll(profile, jobs, nil)
+			emit(arg, result)
+		} else {
+			result = compiler.BuildAll(profile, jobs)
+		}
+		if result.Failed > 0 || len(result.Entries) == 0 {
+			os.Exit(1)
+		}
+
+	case "toolchain":
+		runToolchain(arg)
+
+	case "server":
+		runServer(arg)
+
+	case "rcon":
+		runRCON(arg)
+
+	case "cfg":
+		runCfg(arg)
+
+	case "migrate-config":
+		reverse := takeSwitch("--reverse")
+		force := takeSwitch("--force")
+		dryRun := takeSwitch("--dry-run")
+		if report.Machine() {
+			_, m, err := servercfg.Migrate(".", reverse, force, dryRun)
+			if err != nil {
+				report.Fail(arg, err, 1)
+			}
+			emit(arg, m)
+			return
+		}
+		if _, err := servercfg.MigrateConfig(reverse, force, dryRun); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+	case "migrate-source":
+		runMigrateSource(arg)
+
+	case "query":
+		address := getArg(2)
+		if address == "" {
+			address = rcon.Local().Address
+		}
+		if report.Machine() {
+			result, err := rcon.Query(address)
+			if err != nil {
+				report.Fail(arg, err, 1)
+			}
+			emit(arg, result)
+			return
+		}
+		if err := rcon.ShowQuery(address); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
 
 	case "--semantic":
 		target := getArg(2)
@@ -209,7 +436,8 @@ func main() {
 	default:
 		// Check if it's a .pwn file
 		if len(arg) > 4 && arg[len(arg)-4:] == ".pwn" {
-			compiler.Compile(arg, compiler.ProfileAuto)
+			profile := takeFlag("--profile")
+			runCompile("--compile", arg, compiler.Profile(profile))
 		} else {
 			fmt.Printf("Unknown command: %s\n", arg)
 			fmt.Println("Use --help for usage information.")
@@ -218,6 +446,518 @@ func main() {
 	}
 }
 
+// machineCommands lists the commands that can emit --json/--format output
+var machineCommands = map[string]bool{
+	"--version":      true,
+	"-v":             true,
+	"--compile":      true,
+	"-c":             true,
+	"--doctor":       true,
+	"--audit":        true,
+	"--install":      true,
+	"-i":             true,
+	"--plugins":      true,
+	"install":        true,
+	"outdated":       true,
+	"registry":       true,
+	"plugins":        true,
+	"--search":       true,
+	"--verify":       true,
+	"--deps":         true,
+	"--uninstall":    true,
+	"--lint":         true,
+	"--analytics":    true,
+	"--bench":        true,
+	"--matrix":       true,
+	"--build-all":    true,
+	"toolchain":      true,
+	"server":         true,
+	"rcon":           true,
+	"query":          true,
+	"cfg":            true,
+	"migrate-config": true,
+	"migrate-source": true,
+}
+
+// parseGlobalFlags removes output format flags from args wherever they appear
+func parseGlobalFlags(args []string) ([]string, error) {
+	var rest []string
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		switch {
+		case a == "--json":
+			report.Current = report.FormatJSON
+		case a == "--format":
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("--format requires a value (text, json or sarif)")
+			}
+			i++
+			format, err := report.ParseFormat(args[i])
+			if err != nil {
+				return nil, err
+			}
+			report.Current = format
+		case strings.HasPrefix(a, "--format="):
+			format, err := report.ParseFormat(strings.TrimPrefix(a, "--format="))
+			if err != nil {
+				return nil, err
+			}
+			report.Current = format
+		default:
+			rest = append(rest, a)
+		}
+	}
+	return rest, nil
+}
+
+// emit writes a command result in the selected machine format
+func emit(command string, v interface{}) {
+	if err := report.Emit(command, v); err != nil {
+		report.Fail(command, err, 2)
+	}
+}
+
+func runCompile(command, target string, profile compiler.Profile) {
+	if profile == "" {
+		profile = compiler.ProfileAuto
+	}
+
+	var result *compiler.CompileResult
+	if report.Machine() {
+		result = compiler.Build(target, profile)
+		emit(command, result)
+	} else {
+		result = compiler.Compile(target, profile)
+	}
+	if !result.Success {
+		os.Exit(1)
+	}
+}
+
+// runInstall installs the named plugins ("name", "name@constraint" or a
+// git URL, path or download URL) and their dependencies, or with no names
+// restores the exact set recorded in fpawn.lock
+func runInstall(command string) {
+	client := plugins.NewClient(takeFlag("--source"))
+	client.Offline = takeSwitch("--offline")
+	plugins.SetOffline(client.Offline)
+	opts := plugins.InstallOptions{
+		Force:  takeSwitch("--force"),
+		DryRun: takeSwitch("--dry-run"),
+	}
+	var pack *plugins.Pack
+	if from := takeFlag("--from"); from != "" {
+		var err error
+		if pack, err = plugins.ImportPack(from, client); err != nil {
+			report.Fail(command, err, 1)
+		}
+		if !report.Machine() {
+			fmt.Printf(" %s Imported %d plugin(s) from %s\n", core.Cyan("[Pack]"), len(pack.Plugins), from)
+		}
+	}
+	specs := os.Args[2:]
+
+	if len(specs) == 0 {
+		// A fresh project given only a pack has no lock to restore; the
+		// pack itself says what to install
+		restore, install := plugins.RestoreLocked, plugins.InstallLocked
+		if pack != nil {
+			if lock, err := plugins.LoadLock(); err == nil && len(lock.Plugins) == 0 {
+				restore = func(c *plugins.Client) ([]plugins.RestoredPlugin, error) { return plugins.RestorePack(pack, c) }
+				install = func(c *plugins.Client) ([]plugins.RestoredPlugin, error) { return plugins.InstallPack(pack, c) }
+			}
+		}
+		if report.Machine() {
+			result, err := restore(client)
+			if err != nil {
+				report.Fail(command, err, 1)
+			}
+			emit(command, result)
+			for _, r := range result {
+				if r.Status == "failed" {
+					os.Exit(1)
+				}
+			}
+			return
+		}
+		if _, err := install(client); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if report.Machine() {
+		if !opts.DryRun {
+			report.Fail(command, fmt.Errorf("--json needs --dry-run when installing plugins"), 2)
+		}
+		lock, err := plugins.LoadLock()
+		if err != nil {
+			report.Fail(command, err, 1)
+		}
+		plan, err := plugins.PlanInstall(specs, client, lock, opts.Force)
+		if err != nil {
+			report.Fail(command, err, 1)
+		}
+		emit(command, plan)
+		return
+	}
+	if _, err := plugins.InstallPlugins(specs, client, opts); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// runPlugins dispatches "fpawn plugins list|pack"
+func runPlugins(command string) {
+	switch sub := getArg(2); sub {
+	case "list", "":
+		if report.Machine() {
+			emit(command, plugins.Catalogue())
+			return
+		}
+		plugins.ListPlugins()
+	case "pack":
+		client := plugins.NewClient(takeFlag("--source"))
+		out := takeFlag("-o")
+		if out == "" {
+			out = plugins.DefaultPack
+		}
+		names := os.Args[3:]
+		if report.Machine() {
+			result, err := plugins.BuildPack(names, out, client)
+			if err != nil {
+				report.Fail(command, err, 1)
+			}
+			emit(command, result)
+			return
+		}
+		if _, err := plugins.PackPlugins(names, out, client); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	default:
+		fmt.Println("Usage: fpawn plugins <list|pack [name...] [-o file]>")
+		os.Exit(1)
+	}
+}
+
+// runMigrateSource rewrites a_samp sources for open.mp. The diff is shown
+// first; nothing changes without --apply.
+func runMigrateSource(command string) {
+	yes := takeSwitch("--yes")
+	opts := tools.MigrateOptions{
+		Apply:  takeSwitch("--apply") || yes,
+		Verify: !takeSwitch("--no-verify"),
+		Jobs:   core.ToInt(takeFlag("--jobs")),
+	}
+	files := os.Args[2:]
+	if len(files) == 1 && strings.HasSuffix(files[0], ".pwn") {
+		opts.Entry = files[0]
+	}
+
+	if report.Machine() {
+		result, err := tools.RunOpenMPMigration(files, opts)
+		if err != nil {
+			report.Fail(command, err, 1)
+		}
+		emit(command, result)
+		if result.RolledBack {
+			os.Exit(1)
+		}
+		return
+	}
+	if _, err := tools.MigrateToOpenMP(files, opts, !yes); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// runToolchain dispatches "fpawn toolchain install|list|use"
+func runToolchain(command string) {
+	source := toolchain.NewGitHubSource(takeFlag("--source"))
+	global := takeSwitch("--global")
+	sub, version := getArg(2), getArg(3)
+
+	switch sub {
+	case "install":
+		if report.Machine() {
+			pin := toolchain.ProjectPin()
+			expected := ""
+			if version == "" && pin != nil {
+				version = pin.Version
+			}
+			if pin != nil && toolchain.Normalize(pin.Version) == toolchain.Normalize(version) {
+				expected = pin.SHA256
+			}
+			inst, err := toolchain.Install(version, source, expected)
+			if err != nil {
+				report.Fail(command, err, 1)
+			}
+			emit(command, inst)
+			return
+		}
+		if _, err := toolchain.InstallToolchain(version, source); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+	case "list", "":
+		if report.Machine() {
+			emit(command, toolchain.Inspect())
+			return
+		}
+		toolchain.ListToolchains()
+
+	case "use":
+		if version == "" {
+			fmt.Println("Usage: fpawn toolchain use <version> [--global]")
+			os.Exit(1)
+		}
+		if report.Machine() {
+			report.Fail(command, fmt.Errorf("toolchain use does not support --format=%s", report.Current), 2)
+		}
+		if err := toolchain.UseToolchain(version, global); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+	default:
+		fmt.Println("Usage: fpawn toolchain <install [version]|list|use <version>>")
+		os.Exit(1)
+	}
+}
+
+// runServer dispatches "fpawn server start|stop|restart|status|logs"
+func runServer(command string) {
+	follow := takeSwitch("--follow") || takeSwitch("-f")
+	lines := core.ToInt(takeFlag("--lines"))
+	sub := getArg(2)
+
+	if report.Machine() && sub != "status" && sub != "" {
+		report.Fail(command, fmt.Errorf("server %s does not support --format=%s", sub, report.Current), 2)
+	}
+
+	var err error
+	switch sub {
+	case "start":
+		err = server.StartServer()
+	case "stop":
+		err = server.StopServer()
+	case "restart":
+		err = server.RestartServer()
+	case "status", "":
+		if report.Machine() {
+			emit(command, server.Inspect())
+			return
+		}
+		server.ShowStatus()
+	case "logs":
+		err = server.ShowLogs(lines, follow)
+	case server.SuperviseCommand:
+		err = server.Supervise(getArg(3))
+	default:
+		fmt.Println("Usage: fpawn server <start|stop|restart|status|logs [--follow] [--lines n]>")
+		os.Exit(1)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// runRegistry dispatches "fpawn registry list|add|remove|update"
+func runRegistry(command string) {
+	sub, source := getArg(2), getArg(3)
+
+	switch sub {
+	case "list", "", "update":
+		refresh := sub == "update"
+		if report.Machine() {
+			result := plugins.RegistryReport()
+			if refresh {
+				result = plugins.RefreshRegistries()
+			}
+			emit(command, result)
+			return
+		}
+		plugins.ShowRegistries(refresh)
+		return
+	}
+
+	if report.Machine() {
+		report.Fail(command, fmt.Errorf("registry %s does not support --format=%s", sub, report.Current), 2)
+	}
+	var err error
+	switch sub {
+	case "add":
+		if source == "" {
+			err = fmt.Errorf("usage: fpawn registry add <url|file>")
+			break
+		}
+		if err = plugins.AddRegistry(source); err == nil {
+			fmt.Printf(" %s Added registry %s\n", core.Green("✓"), source)
+		}
+	case "remove":
+		if source == "" {
+			err = fmt.Errorf("usage: fpawn registry remove <url|file>")
+			break
+		}
+		if err = plugins.RemoveRegistry(source); err == nil {
+			fmt.Printf(" %s Removed registry %s\n", core.Green("✓"), source)
+		}
+	default:
+		fmt.Println("Usage: fpawn registry <list|add <url|file>|remove <url|file>|update>")
+		os.Exit(1)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// runCfg dispatches "fpawn cfg get|set|add-plugin|remove-plugin"
+func runCfg(command string) {
+	sub, key, value := getArg(2), getArg(3), strings.Join(os.Args[min(4, len(os.Args)):], " ")
+
+	if report.Machine() && sub != "get" && sub != "" {
+		report.Fail(command, fmt.Errorf("cfg %s does not support --format=%s", sub, report.Current), 2)
+	}
+
+	var err error
+	switch sub {
+	case "get", "":
+		cfg, openErr := servercfg.Open()
+		if openErr != nil {
+			err = openErr
+			break
+		}
+		if key == "" {
+			if report.Machine() {
+				emit(command, cfg.Settings())
+				return
+			}
+			fmt.Printf("\n %s %s\n", core.LBlue("⚙"), core.Bold("Server Configuration"))
+			fmt.Println(" ──────────────────────────────────────────────────")
+			_, err = servercfg.ShowSettings()
+			fmt.Println()
+			break
+		}
+		v, ok := cfg.Get(key)
+		if report.Machine() {
+			emit(command, map[string]interface{}{"key": key, "value": v, "set": ok})
+			return
+		}
+		if !ok {
+			err = fmt.Errorf("%s is not set in %s", key, cfg.Path)
+			break
+		}
+		fmt.Println(v)
+	case "set":
+		if key == "" {
+			err = fmt.Errorf("usage: fpawn cfg set <key> <value>")
+			break
+		}
+		err = servercfg.SetValue(key, value)
+	case "add-plugin":
+		if key == "" {
+			err = fmt.Errorf("usage: fpawn cfg add-plugin <name>")
+			break
+		}
+		err = servercfg.AddPluginEntry(key)
+	case "remove-plugin":
+		if key == "" {
+			err = fmt.Errorf("usage: fpawn cfg remove-plugin <name>")
+			break
+		}
+		err = servercfg.RemovePluginEntry(key)
+	default:
+		fmt.Println("Usage: fpawn cfg <get [key]|set <key> <value>|add-plugin <name>|remove-plugin <name>>")
+		fmt.Printf("Keys: %s\n", strings.Join(servercfg.Keys(), ", "))
+		os.Exit(1)
+	}
+	if err != nil {
+		report.Fail(command, err, 1)
+	}
+}
+
+// runRCON dispatches "fpawn rcon <command>" and "fpawn rcon --interactive"
+func runRCON(command string) {
+	target := rcon.Local()
+	if host := takeFlag("--host"); host != "" {
+		target.Address = host
+	}
+	if password := takeFlag("--password"); password != "" {
+		target.Password = password
+	}
+	interactive := takeSwitch("--interactive") || takeSwitch("-i")
+	cmd := strings.Join(os.Args[2:], " ")
+
+	if interactive {
+		if report.Machine() {
+			report.Fail(command, fmt.Errorf("rcon --interactive does not support --format=%s", report.Current), 2)
+		}
+		if err := rcon.Interactive(target); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	if cmd == "" {
+		fmt.Println("Usage: fpawn rcon \"<command>\" | --interactive [--host host:port] [--password pw]")
+		os.Exit(1)
+	}
+
+	if report.Machine() {
+		output, err := rcon.Send(target, cmd)
+		if err != nil {
+			report.Fail(command, err, 1)
+		}
+		emit(command, map[string]interface{}{"address": target.Address, "command": cmd, "output": output})
+		return
+	}
+	if err := rcon.SendCommand(target, cmd); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// isTerminal reports whether f is an interactive terminal rather than a pipe or file
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// takeSwitch removes a boolean "--name" from the command arguments and
+// reports whether it was present
+func takeSwitch(name string) bool {
+	for i := 2; i < len(os.Args); i++ {
+		if os.Args[i] == name {
+			os.Args = append(os.Args[:i], os.Args[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// takeFlag removes "--name value" or "--name=value" from the command
+// arguments and returns the value, or "" when the flag is absent
+func takeFlag(name string) string {
+	for i := 2; i < len(os.Args); i++ {
+		a := os.Args[i]
+		if a == name && i+1 < len(os.Args) {
+			value := os.Args[i+1]
+			os.Args = append(os.Args[:i], os.Args[i+2:]...)
+			return value
+		}
+		if strings.HasPrefix(a, name+"=") {
+			os.Args = append(os.Args[:i], os.Args[i+1:]...)
+			return strings.TrimPrefix(a, name+"=")
+		}
+	}
+	return ""
+}
+
 func getArg(index int) string {
 	if len(os.Args) > index {
 		return os.Args[index]
@@ -247,7 +987,6 @@ func runLegacyShell() {
 	}
 }
 
-
 func showHelp() {
 	fmt.Println()
 	fmt.Println(" " + core.Bold("fpawn v"+version+" - Intelligence Frontier (Go Edition)"))
@@ -260,13 +999,52 @@ func showHelp() {
 
 	fmt.Println(" " + core.Bold("COMPILATION:"))
 	fmt.Println("   -c, --compile [file]     Compile script")
+	fmt.Println("       --profile <name>     Use a build profile from pawn.json/pawn.yaml")
 	fmt.Println("   -w, --watch [file]       Watch mode (auto-recompile)")
-	fmt.Println("       --run                Start server")
-	fmt.Println("       --matrix [file]      Multi-profile build")
-	fmt.Println("       --bench [file]       Compilation benchmark")
+	fmt.Println("       --run                (Re)start the server under the supervisor")
+	fmt.Println("       --matrix [file]      Build every project profile in parallel")
+	fmt.Println("       --build-all          Build every gamemode, filterscript and npcmode")
+	fmt.Println("       --jobs <n>           Parallel builds for --matrix/--build-all (default: CPU count)")
+	fmt.Println("       --bench [file]       Compilation benchmark (history in .fpawn/bench.json)")
+	fmt.Println("       --iterations <n>     Timed benchmark runs (default: 5)")
+	fmt.Println("       --warmup <n>         Untimed runs before measuring (default: 1)")
 	fmt.Println("       --sync               Sync include libraries")
 	fmt.Println()
 
+	fmt.Println(" " + core.Bold("TOOLCHAIN:"))
+	fmt.Println("   toolchain install [ver]  Download a pawncc release (default: project pin)")
+	fmt.Println("   toolchain list           Show installed compilers")
+	fmt.Println("   toolchain use <ver>      Pin a version in pawn.json/pawn.yaml")
+	fmt.Println("       --global             Set the user default instead of pinning")
+	fmt.Println("       --source <url>       GitHub API mirror to download from")
+	fmt.Println()
+
+	fmt.Println(" " + core.Bold("SERVER:"))
+	fmt.Println("   server start             Run the server under a supervisor that restarts it on crash")
+	fmt.Println("   server stop|restart      Stop or restart the supervised server")
+	fmt.Println("   server status            Show PID, uptime and restart count (.fpawn/server.pid)")
+	fmt.Println("   server logs              Show captured output (.fpawn/server.log)")
+	fmt.Println("       --follow, -f         Keep printing new output")
+	fmt.Println("       --lines <n>          Lines to show (default: 50)")
+	fmt.Println("   rcon \"<command>\"         Send an RCON command (gmx, reloadfs <name>...)")
+	fmt.Println("   rcon --interactive       Open an RCON console")
+	fmt.Println("       --host <host:port>   Server address (default: port from server.cfg)")
+	fmt.Println("       --password <pw>      RCON password (default: rcon_password from server.cfg)")
+	fmt.Println("   query [host:port]        Show server info, rules and players")
+	fmt.Println("   cfg get [key]            Show config.json/server.cfg, or one key")
+	fmt.Println("   cfg set <key> <value>    Change a key (hostname, port, rcon_password, gamemodes...)")
+	fmt.Println("   cfg add-plugin <name>    Load a plugin")
+	fmt.Println("   cfg remove-plugin <name> Stop loading a plugin")
+	fmt.Println("   migrate-config           Convert server.cfg to open.mp config.json")
+	fmt.Println("       --reverse            Convert config.json back to server.cfg")
+	fmt.Println("       --dry-run            Print the result instead of writing it")
+	fmt.Println("       --force              Overwrite an existing target file")
+	fmt.Println("   migrate-source [files]   Show the a_samp → open.mp rewrite of the sources as a diff")
+	fmt.Println("       --apply              Apply it (asks first; keeps .bak backups)")
+	fmt.Println("       --yes                Apply without asking")
+	fmt.Println("       --no-verify          Skip the matrix build before and after")
+	fmt.Println()
+
 	fmt.Println(" " + core.Bold("ANALYSIS:"))
 	fmt.Println("       --doctor [file]      Health check & diagnostics")
 	fmt.Println("       --audit [file]       Deep security scan")
@@ -284,11 +1062,32 @@ func showHelp() {
 
 	fmt.Println(" " + core.Bold("PLUGINS:"))
 	fmt.Println("   -i, --install <name>     Install plugin")
-	fmt.Println("       --uninstall <name>   Remove plugin")
+	fmt.Println("   install [name...]        Install plugins, or restore exactly what fpawn.lock records")
+	fmt.Println("                            name@^2.13 / ~2.13 / \">=2.0 <3\" constrains the release")
+	fmt.Println("                            github:owner/repo[@tag], a git URL[#ref], ./local/path")
+	fmt.Println("                            or https://host/file.zip installs from there;")
+	fmt.Println("                            name=<source> names the plugin")
+	fmt.Println("       --dry-run            Print the install plan only")
+	fmt.Println("       --force              Install plugins built for the other ecosystem")
+	fmt.Println("       --offline            Use the download cache only, never the network")
+	fmt.Println("       --from <pack>        Install from a pack made by 'plugins pack'")
+	fmt.Println("   outdated                 List locked plugins with a newer release")
+	fmt.Println("   upgrade <name>           Move a plugin to its latest release (--tag <release> to pick one)")
+	fmt.Println("       --source <url>       GitHub API mirror for install/outdated/upgrade")
+	fmt.Println("       --uninstall <name>   Remove exactly the files a plugin installed; rolls")
+	fmt.Println("                            back on failure (--force: even if others need it)")
+	fmt.Println("   plugins pack [name...]   Export locked plugins and their dependencies for")
+	fmt.Println("                            air-gapped installs (-o file, default " + plugins.DefaultPack + ")")
+	fmt.Println("   registry [list|update]   Show the plugin indexes in use, or re-fetch them")
+	fmt.Println("   registry add <url|file>  Use a private index ahead of the others")
+	fmt.Println("   registry remove <src>    Stop using an index")
 	fmt.Println("       --plugins            List all plugins")
 	fmt.Println("       --search <query>     Search plugins")
-	fmt.Println("       --verify             Verify plugin integrity")
-	fmt.Println("       --deps               Check dependencies")
+	fmt.Println("       --verify [--strict]  Check plugin SHA-256 and architecture; exits 1 on")
+	fmt.Println("                            tampering (--strict: unknown binaries too)")
+	fmt.Println("       --deps [file]        Check loaded plugins against the natives the")
+	fmt.Println("                            gamemode calls; exits 1 if one is missing")
+	fmt.Println("                            or loaded before its dependencies")
 	fmt.Println()
 
 	fmt.Println(" " + core.Bold("TOOLS:"))
@@ -300,6 +1099,8 @@ func showHelp() {
 	fmt.Println()
 
 	fmt.Println(" " + core.Bold("SYSTEM:"))
+	fmt.Println("       --json               Emit a single JSON document (same as --format=json)")
+	fmt.Println("       --format <fmt>       Output format: text, json or sarif")
 	fmt.Println("       --lang <id|en>       Set language")
 	fmt.Println("       --update             Check for updates")
 	fmt.Println("   -v, --version            Show version")
@@ -316,5 +1117,6 @@ func showHelp() {
 	fmt.Println("   fpawn --install mysql")
 	fmt.Println("   fpawn --template roleplay")
 	fmt.Println("   fpawn --doctor")
+	fmt.Println("   fpawn toolchain install 3.10.11")
 	fmt.Println()
 }
@@ -13,17 +13,18 @@ import (
 
 // PerformanceMetrics holds performance analysis results
 type PerformanceMetrics struct {
-	TotalLines      int
-	CodeLines       int
-	CommentLines    int
-	BlankLines      int
-	FunctionCount   int
-	CallbackCount   int
-	TimerCount      int
-	QueryCount      int
-	IncludeCount    int
-	GlobalVars      int
-	ComplexityScore int
+	TotalLines      int           `json:"total_lines"`
+	CodeLines       int           `json:"code_lines"`
+	CommentLines    int           `json:"comment_lines"`
+	BlankLines      int           `json:"blank_lines"`
+	FunctionCount   int           `json:"function_count"`
+	CallbackCount   int           `json:"callback_count"`
+	TimerCount      int           `json:"timer_count"`
+	QueryCount      int           `json:"query_count"`
+	IncludeCount    int           `json:"include_count"`
+	GlobalVars      int           `json:"global_vars"`
+	ComplexityScore int           `json:"complexity_score"`
+	AnalysisTime    time.Duration `json:"analysis_time_ns"`
 }
 
 // PerformanceAnalytics performs deep performance analysis
@@ -31,6 +32,13 @@ func PerformanceAnalytics(target string) *PerformanceMetrics {
 	fmt.Printf("\n %s %s\n", core.LBlue("📊"), core.Bold("Performance Analytics"))
 	fmt.Println(" ──────────────────────────────────────────────────")
 
+	metrics := AnalyzePerformance(target)
+	PrintPerformanceReport(metrics)
+	return metrics
+}
+
+// AnalyzePerformance collects code metrics without printing anything
+func AnalyzePerformance(target string) *PerformanceMetrics {
 	metrics := &PerformanceMetrics{}
 
 	// Scan all .pwn and .inc files
@@ -48,12 +56,16 @@ func PerformanceAnalytics(target string) *PerformanceMetrics {
 		analyzeFile(file, metrics)
 	}
 
-	elapsed := time.Since(startTime)
+	metrics.AnalysisTime = time.Since(startTime)
 
 	// Calculate complexity score
 	metrics.ComplexityScore = calculateComplexity(metrics)
 
-	// Print results
+	return metrics
+}
+
+// PrintPerformanceReport renders code metrics for the terminal
+func PrintPerformanceReport(metrics *PerformanceMetrics) {
 	fmt.Printf("\n %s\n", core.Bold("Code Metrics:"))
 	fmt.Printf("   Total Lines:      %d\n", metrics.TotalLines)
 	fmt.Printf("   Code Lines:       %d\n", metrics.CodeLines)
@@ -89,7 +101,7 @@ func PerformanceAnalytics(target string) *PerformanceMetrics {
 	}
 
 	fmt.Printf(" Complexity Score: %s (%d)\n", complexityColor(complexityRating), metrics.ComplexityScore)
-	fmt.Printf(" Analysis Time: %v\n", elapsed)
+	fmt.Printf(" Analysis Time: %v\n", metrics.AnalysisTime)
 
 	// Suggestions
 	if metrics.TimerCount > 20 {
@@ -101,8 +113,6 @@ func PerformanceAnalytics(target string) *PerformanceMetrics {
 	if metrics.GlobalVars > 100 {
 		fmt.Printf(" %s Many global variables (%d). Consider refactoring.\n", core.Yellow("⚠"), metrics.GlobalVars)
 	}
-
-	return metrics
 }
 
 func analyzeFile(path string, metrics *PerformanceMetrics) {
@@ -342,7 +352,7 @@ func SuggestionEngine(target string) {
 	// Check for a_samp vs open.mp
 	if strings.Contains(content, "#include <a_samp>") && !strings.Contains(content, "#include <open.mp>") {
 		fmt.Printf(" %s Using legacy a_samp include\n", core.Yellow("⚠"))
-		fmt.Printf("   %s Consider migrating to open.mp for better performance and features\n\n", core.Cyan("→"))
+		fmt.Printf("   %s Run `fpawn migrate-source` to preview the open.mp migration as a diff\n\n", core.Cyan("→"))
 		suggestions++
 	}
 
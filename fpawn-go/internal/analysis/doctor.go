@@ -11,26 +11,39 @@ import (
 
 	"github.com/FerzDevZ/fpawn/internal/compiler"
 	"github.com/FerzDevZ/fpawn/internal/core"
+	"github.com/FerzDevZ/fpawn/internal/report"
 )
 
 // DoctorResult holds the results of a project health check
 type DoctorResult struct {
-	CriticalIssues []Issue
-	Warnings       []Issue
-	Healthy        bool
+	Target         string  `json:"target"`
+	CriticalIssues []Issue `json:"critical_issues"`
+	Warnings       []Issue `json:"warnings"`
+	Healthy        bool    `json:"healthy"`
 }
 
 // Issue represents a code issue found during analysis
 type Issue struct {
-	Type        string
-	Description string
-	File        string
-	Line        int
-	Suggestion  string
+	Type        string `json:"type"`
+	Description string `json:"description"`
+	File        string `json:"file,omitempty"`
+	Line        int    `json:"line,omitempty"`
+	Suggestion  string `json:"suggestion,omitempty"`
 }
 
 // ProjectDoctor performs a comprehensive health check on the project
 func ProjectDoctor(target string) *DoctorResult {
+	fmt.Printf("\n %s %s\n", core.LBlue("🏥"), core.Bold("Project Doctor"))
+	fmt.Println(" ──────────────────────────────────────────────────")
+	fmt.Printf(" %s %s\n\n", core.Cyan("[Scan]"), core.Msg("doc_analyzing"))
+
+	result := Diagnose(target)
+	PrintDoctorReport(result)
+	return result
+}
+
+// Diagnose runs the health check without printing anything
+func Diagnose(target string) *DoctorResult {
 	result := &DoctorResult{
 		Healthy: true,
 	}
@@ -47,10 +60,7 @@ func ProjectDoctor(target string) *DoctorResult {
 		result.Healthy = false
 		return result
 	}
-
-	fmt.Printf("\n %s %s\n", core.LBlue("🏥"), core.Bold("Project Doctor"))
-	fmt.Println(" ──────────────────────────────────────────────────")
-	fmt.Printf(" %s %s\n\n", core.Cyan("[Scan]"), core.Msg("doc_analyzing"))
+	result.Target = target
 
 	// Read file content
 	data, err := os.ReadFile(target)
@@ -166,7 +176,11 @@ func ProjectDoctor(target string) *DoctorResult {
 		})
 	}
 
-	// Print results
+	return result
+}
+
+// PrintDoctorReport renders a doctor result for the terminal
+func PrintDoctorReport(result *DoctorResult) {
 	if len(result.CriticalIssues) > 0 {
 		fmt.Printf(" %s\n", core.Red(core.Bold("Critical Issues:")))
 		for _, issue := range result.CriticalIssues {
@@ -202,32 +216,72 @@ func ProjectDoctor(target string) *DoctorResult {
 			len(result.CriticalIssues),
 			len(result.Warnings))
 	}
+}
 
-	return result
+// Findings exposes the doctor issues for SARIF output
+func (r *DoctorResult) Findings() []report.Finding {
+	var findings []report.Finding
+	for _, issue := range r.CriticalIssues {
+		findings = append(findings, issue.finding("error"))
+	}
+	for _, issue := range r.Warnings {
+		findings = append(findings, issue.finding("warning"))
+	}
+	return findings
 }
 
-// SecurityAudit performs a deep security scan
-func SecurityAudit(target string) {
-	if target == "" {
-		target = compiler.FindEntryPoint()
+func (i Issue) finding(level string) report.Finding {
+	message := i.Description
+	if i.Suggestion != "" {
+		message += ". " + i.Suggestion
+	}
+	return report.Finding{
+		RuleID:    "fpawn." + strings.ToLower(i.Type),
+		Level:     level,
+		Message:   message,
+		File:      i.File,
+		StartLine: i.Line,
+		EndLine:   i.Line,
 	}
+}
+
+// AuditResult holds the findings of a security audit
+type AuditResult struct {
+	Target string  `json:"target"`
+	Issues []Issue `json:"issues"`
+}
 
+// SecurityAudit performs a deep security scan
+func SecurityAudit(target string) *AuditResult {
 	fmt.Printf("\n %s %s\n", core.Red("🛡️"), core.Bold(core.Msg("aud_title")))
 	fmt.Println(" ──────────────────────────────────────────────────")
 
+	result, err := AuditSource(target)
+	if err != nil {
+		fmt.Printf(" %s %v\n", core.Red("[Error]"), err)
+		return nil
+	}
+
+	PrintAuditReport(result)
+	return result
+}
+
+// AuditSource scans target for security risks without printing anything
+func AuditSource(target string) (*AuditResult, error) {
+	if target == "" {
+		target = compiler.FindEntryPoint()
+	}
 	if target == "" {
-		fmt.Printf(" %s %s\n", core.Red("[Error]"), core.Msg("entry_err"))
-		return
+		return nil, fmt.Errorf("%s", core.Msg("entry_err"))
 	}
 
 	file, err := os.Open(target)
 	if err != nil {
-		fmt.Printf(" %s Cannot read file\n", core.Red("[Error]"))
-		return
+		return nil, fmt.Errorf("cannot read file: %v", err)
 	}
 	defer file.Close()
 
-	issues := 0
+	result := &AuditResult{Target: target}
 	scanner := bufio.NewScanner(file)
 	lineNum := 0
 
@@ -238,49 +292,82 @@ func SecurityAudit(target string) {
 	remoteFuncRisk := regexp.MustCompile(`CallRemoteFunction\s*\(.*(input|name|cmd)`)
 	httpInjection := regexp.MustCompile(`HTTP\s*\(.*(input|url|path)`)
 
+	add := func(kind, description string) {
+		result.Issues = append(result.Issues, Issue{
+			Type:        kind,
+			Description: description,
+			File:        target,
+			Line:        lineNum,
+		})
+	}
+
 	for scanner.Scan() {
 		line := scanner.Text()
 		lineNum++
 
 		// SQL Injection
 		if sqliPattern.MatchString(line) && !strings.Contains(line, "mysql_format") {
-			fmt.Printf(" %s Line %d: %s\n", core.Red("[CRITICAL]"), lineNum, core.Msg("aud_sqli"))
-			issues++
+			add("CRITICAL", core.Msg("aud_sqli"))
 		}
 
 		// Command Injection
 		if cmdInjection.MatchString(line) {
-			fmt.Printf(" %s Line %d: Potential command injection via RCON\n", core.Red("[CRITICAL]"), lineNum)
-			issues++
+			add("CRITICAL", "Potential command injection via RCON")
 		}
 
 		// Unsafe repeating timer
 		if unsafeTimer.MatchString(line) {
 			if !strings.Contains(line, "IsPlayerConnected") && !strings.Contains(line, "GetPlayerPoolSize") {
-				fmt.Printf(" %s Line %d: %s\n", core.Orange("[WARN]"), lineNum, core.Msg("aud_timer"))
-				issues++
+				add("WARN", core.Msg("aud_timer"))
 			}
 		}
 
 		// Remote Function Risk
 		if remoteFuncRisk.MatchString(line) {
-			fmt.Printf(" %s Line %d: Unsafe data passed to CallRemoteFunction\n", core.Yellow("[RISK]"), lineNum)
-			issues++
+			add("RISK", "Unsafe data passed to CallRemoteFunction")
 		}
 
 		// HTTP Injection
 		if httpInjection.MatchString(line) {
-			fmt.Printf(" %s Line %d: Unsafe URL/Path in HTTP request\n", core.Red("[CRITICAL]"), lineNum)
-			issues++
+			add("CRITICAL", "Unsafe URL/Path in HTTP request")
+		}
+	}
+
+	return result, nil
+}
+
+// PrintAuditReport renders an audit result for the terminal
+func PrintAuditReport(result *AuditResult) {
+	for _, issue := range result.Issues {
+		tag := core.Red("[" + issue.Type + "]")
+		switch issue.Type {
+		case "WARN":
+			tag = core.Orange("[WARN]")
+		case "RISK":
+			tag = core.Yellow("[RISK]")
 		}
+		fmt.Printf(" %s Line %d: %s\n", tag, issue.Line, issue.Description)
 	}
 
 	fmt.Println(" ──────────────────────────────────────────────────")
-	if issues == 0 {
+	if len(result.Issues) == 0 {
 		fmt.Printf(" %s %s\n", core.Green("✓"), core.Msg("aud_clean"))
 	} else {
-		fmt.Printf(" %s Found %d potential security issue(s)\n", core.Red("✗"), issues)
+		fmt.Printf(" %s Found %d potential security issue(s)\n", core.Red("✗"), len(result.Issues))
+	}
+}
+
+// Findings exposes the audit issues for SARIF output
+func (r *AuditResult) Findings() []report.Finding {
+	var findings []report.Finding
+	for _, issue := range r.Issues {
+		level := "warning"
+		if issue.Type == "CRITICAL" {
+			level = "error"
+		}
+		findings = append(findings, issue.finding(level))
 	}
+	return findings
 }
 
 // OmniscientScan performs a recursive deep scan of the entire project tree
@@ -0,0 +1,272 @@
+package compiler
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/FerzDevZ/fpawn/internal/core"
+)
+
+// benchHistoryFile keeps every benchmark run so regressions show up across commits
+var benchHistoryFile = filepath.Join(".fpawn", "bench.json")
+
+// BenchOptions tweaks a benchmark run
+type BenchOptions struct {
+	Iterations int
+	Warmup     int
+	Profile    Profile
+}
+
+// BenchResult summarises a compilation benchmark; times are in seconds
+type BenchResult struct {
+	Target     string    `json:"target"`
+	Profile    Profile   `json:"profile"`
+	Commit     string    `json:"commit,omitempty"`
+	Timestamp  time.Time `json:"timestamp"`
+	Iterations int       `json:"iterations"`
+	Warmup     int       `json:"warmup"`
+	Successes  int       `json:"successes"`
+	Samples    []float64 `json:"samples"`
+	Min        float64   `json:"min"`
+	Median     float64   `json:"median"`
+	P95        float64   `json:"p95"`
+	Average    float64   `json:"average"`
+	StdDev     float64   `json:"stddev"`
+	AMXSize    int64     `json:"amx_size"`
+	Stats      *AMXStats `json:"stats,omitempty"`
+
+	// Previous is the last recorded run for the same target and profile
+	Previous *BenchResult `json:"previous,omitempty"`
+}
+
+// Benchmark performs compilation benchmark
+func Benchmark(target string, iterations int) *BenchResult {
+	return BenchmarkWithOptions(target, BenchOptions{Iterations: iterations, Warmup: 1})
+}
+
+// BenchmarkWithOptions is Benchmark with explicit warmup and profile
+func BenchmarkWithOptions(target string, opts BenchOptions) *BenchResult {
+	if target == "" {
+		target = FindEntryPoint()
+	}
+
+	fmt.Printf("\n %s %s\n", core.LBlue("⚡"), core.Bold("Compilation Benchmark"))
+	fmt.Println(" ──────────────────────────────────────────────────")
+
+	if target == "" {
+		fmt.Printf(" %s No target file found\n", core.Red("[Error]"))
+		return nil
+	}
+
+	opts = benchDefaults(opts)
+	fmt.Printf(" Target: %s\n", target)
+	fmt.Printf(" Iterations: %d (+%d warmup)\n\n", opts.Iterations, opts.Warmup)
+
+	bench, err := RunBenchmark(target, opts, func(i int, result *CompileResult) {
+		if i <= 0 {
+			fmt.Printf(" [warmup] Compiling... ")
+		} else {
+			fmt.Printf(" [%d/%d] Compiling... ", i, opts.Iterations)
+		}
+		if result.Success {
+			fmt.Printf("%s (%.3fs)\n", core.Green("OK"), result.Duration)
+		} else {
+			fmt.Printf("%s\n", core.Red("FAIL"))
+		}
+	})
+	if err != nil {
+		fmt.Printf(" %s %v\n", core.Red("[Error]"), err)
+		return nil
+	}
+
+	PrintBenchReport(bench)
+	return bench
+}
+
+// RunBenchmark compiles target repeatedly with the cache bypassed, reporting
+// each iteration to progress (warmup iterations are numbered 0 and below),
+// and appends the result to .fpawn/bench.json
+func RunBenchmark(target string, opts BenchOptions, progress func(int, *CompileResult)) (*BenchResult, error) {
+	if target == "" {
+		target = FindEntryPoint()
+	}
+	if target == "" {
+		return nil, fmt.Errorf("%s", core.Msg("entry_err"))
+	}
+	opts = benchDefaults(opts)
+
+	bench := &BenchResult{
+		Target:     target,
+		Profile:    opts.Profile,
+		Commit:     gitCommit(),
+		Timestamp:  time.Now(),
+		Iterations: opts.Iterations,
+		Warmup:     opts.Warmup,
+	}
+
+	for i := 1 - opts.Warmup; i <= opts.Iterations; i++ {
+		result := BuildWithOptions(target, opts.Profile, BuildOptions{Force: true})
+		if progress != nil {
+			progress(i, result)
+		}
+		if i <= 0 || !result.Success {
+			continue
+		}
+		bench.Profile = result.Profile
+		bench.Successes++
+		bench.Samples = append(bench.Samples, result.Duration)
+		bench.AMXSize = result.AMXSize
+		bench.Stats = result.Stats
+	}
+
+	bench.summarize()
+	if bench.Successes > 0 {
+		bench.Previous = lastBench(target, bench.Profile)
+		if err := appendBenchHistory(bench); err != nil {
+			return bench, err
+		}
+	}
+	return bench, nil
+}
+
+// PrintBenchReport renders the timing statistics and the change since the last run
+func PrintBenchReport(bench *BenchResult) {
+	fmt.Println(" ──────────────────────────────────────────────────")
+
+	if bench.Successes == 0 {
+		fmt.Printf(" %s All compilations failed\n", core.Red("✗"))
+		return
+	}
+
+	fmt.Printf(" Min:     %.3fs\n", bench.Min)
+	fmt.Printf(" Median:  %.3fs\n", bench.Median)
+	fmt.Printf(" p95:     %.3fs\n", bench.P95)
+	fmt.Printf(" Mean:    %.3fs ± %.3fs\n", bench.Average, bench.StdDev)
+	fmt.Printf(" Success rate: %d/%d (%.1f%%)\n", bench.Successes, bench.Iterations, float64(bench.Successes)/float64(bench.Iterations)*100)
+	if bench.AMXSize > 0 {
+		fmt.Printf(" AMX size: %s\n", formatBytes(bench.AMXSize))
+	}
+
+	if prev := bench.Previous; prev != nil && prev.Median > 0 {
+		change := (bench.Median - prev.Median) / prev.Median * 100
+		label := prev.Timestamp.Format("2006-01-02 15:04")
+		if prev.Commit != "" {
+			label = prev.Commit
+		}
+		line := fmt.Sprintf("%+.1f%% median vs %s (%.3fs)", change, label, prev.Median)
+		switch {
+		case change > 10:
+			fmt.Printf(" %s %s\n", core.Red("[Regression]"), line)
+		case change < -10:
+			fmt.Printf(" %s %s\n", core.Green("[Faster]"), line)
+		default:
+			fmt.Printf(" %s %s\n", core.Cyan("[Trend]"), line)
+		}
+	}
+	fmt.Printf(" %s History: %s\n", core.Cyan("[Info]"), benchHistoryFile)
+}
+
+func benchDefaults(opts BenchOptions) BenchOptions {
+	if opts.Iterations < 1 {
+		opts.Iterations = 5
+	}
+	if opts.Warmup < 0 {
+		opts.Warmup = 0
+	}
+	if opts.Profile == "" {
+		opts.Profile = ProfileAuto
+	}
+	return opts
+}
+
+// summarize fills in min, median, p95, mean and sample standard deviation
+func (b *BenchResult) summarize() {
+	n := len(b.Samples)
+	if n == 0 {
+		return
+	}
+
+	sorted := append([]float64(nil), b.Samples...)
+	sort.Float64s(sorted)
+
+	var sum float64
+	for _, s := range sorted {
+		sum += s
+	}
+	b.Min = sorted[0]
+	b.Average = sum / float64(n)
+	if n%2 == 1 {
+		b.Median = sorted[n/2]
+	} else {
+		b.Median = (sorted[n/2-1] + sorted[n/2]) / 2
+	}
+	// Nearest-rank percentile
+	b.P95 = sorted[int(math.Ceil(0.95*float64(n)))-1]
+
+	if n > 1 {
+		var sq float64
+		for _, s := range sorted {
+			sq += (s - b.Average) * (s - b.Average)
+		}
+		b.StdDev = math.Sqrt(sq / float64(n-1))
+	}
+}
+
+// BenchHistory returns every recorded benchmark, oldest first
+func BenchHistory() []*BenchResult {
+	data, err := os.ReadFile(benchHistoryFile)
+	if err != nil {
+		return nil
+	}
+	var history []*BenchResult
+	if err := json.Unmarshal(data, &history); err != nil {
+		return nil
+	}
+	return history
+}
+
+func lastBench(target string, profile Profile) *BenchResult {
+	history := BenchHistory()
+	for i := len(history) - 1; i >= 0; i-- {
+		h := history[i]
+		if filepath.Clean(h.Target) == filepath.Clean(target) && h.Profile == profile {
+			return h
+		}
+	}
+	return nil
+}
+
+func appendBenchHistory(bench *BenchResult) error {
+	entry := *bench
+	entry.Previous = nil
+
+	history := append(BenchHistory(), &entry)
+	if err := os.MkdirAll(filepath.Dir(benchHistoryFile), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(history, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(benchHistoryFile, data, 0644)
+}
+
+// gitCommit returns the short HEAD hash, marked "-dirty" with local changes
+func gitCommit() string {
+	out, err := exec.Command("git", "rev-parse", "--short", "HEAD").Output()
+	if err != nil {
+		return ""
+	}
+	commit := strings.TrimSpace(string(out))
+	if status, err := exec.Command("git", "status", "--porcelain", "--untracked-files=no").Output(); err == nil && len(strings.TrimSpace(string(status))) > 0 {
+		commit += "-dirty"
+	}
+	return commit
+}
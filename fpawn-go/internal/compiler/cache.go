@@ -0,0 +1,245 @@
+package compiler
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/FerzDevZ/fpawn/internal/toolchain"
+)
+
+// cacheDir holds one fingerprint file per target/profile/flags combination
+var cacheDir = filepath.Join(".fpawn", "cache")
+
+var includeDirective = regexp.MustCompile(`^\s*#\s*(?:try)?include\s*([<"])([^>"]+)[>"]`)
+
+// pawncc tries these suffixes, in order, when an include has no extension
+var includeSuffixes = []string{".inc", ".p", ".pawn", ""}
+
+// absentFile stands in a fingerprint for an include candidate that did not
+// exist, so creating it later invalidates the build
+const absentFile = "absent"
+
+// buildFingerprint records everything a successful build depended on
+type buildFingerprint struct {
+	Target       string            `json:"target"`
+	Profile      Profile           `json:"profile"`
+	Args         []string          `json:"args"`
+	Compiler     string            `json:"compiler"`
+	CompilerHash string            `json:"compiler_hash"`
+	Output       string            `json:"output"`
+	Files        map[string]string `json:"files"`
+}
+
+// cacheKey identifies a build by target, profile, compiler and full argument list
+func cacheKey(target string, profile Profile, compiler string, args []string) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%s\x00%s\x00%s", filepath.Clean(target), profile, compiler, strings.Join(args, "\x00"))
+	return fmt.Sprintf("%x", h.Sum(nil))[:16]
+}
+
+// fingerprint hashes the compiler and every file reachable from target
+func fingerprint(target string, profile Profile, compiler string, args, includePaths []string, output string) *buildFingerprint {
+	fp := &buildFingerprint{
+		Target:       target,
+		Profile:      profile,
+		Args:         args,
+		Compiler:     compiler,
+		CompilerHash: getFileHash(compiler),
+		Output:       output,
+		Files:        make(map[string]string),
+	}
+	files, absent := includeGraph(target, compilerIncludePaths(compiler, includePaths))
+	for _, path := range files {
+		fp.Files[path] = getFileHash(path)
+	}
+	for _, path := range absent {
+		fp.Files[path] = absentFile
+	}
+	return fp
+}
+
+// upToDate reports whether a previous successful build matches fp exactly
+// and its AMX is still on disk
+func upToDate(key string, fp *buildFingerprint) bool {
+	if _, err := os.Stat(fp.Output); err != nil {
+		return false
+	}
+
+	data, err := os.ReadFile(filepath.Join(cacheDir, key+".json"))
+	if err != nil {
+		return false
+	}
+	var old buildFingerprint
+	if err := json.Unmarshal(data, &old); err != nil {
+		return false
+	}
+
+	if old.CompilerHash != fp.CompilerHash || old.Output != fp.Output || len(old.Files) != len(fp.Files) {
+		return false
+	}
+	for path, hash := range fp.Files {
+		if old.Files[path] != hash {
+			return false
+		}
+	}
+	return true
+}
+
+// saveFingerprint stores fp after a successful build
+func saveFingerprint(key string, fp *buildFingerprint) error {
+	if err := os.MkdirAll(cacheDir, 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(fp, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(cacheDir, key+".json"), data, 0644)
+}
+
+// compilerIncludePaths appends the include directory pawncc searches
+// implicitly next to its own binary
+func compilerIncludePaths(compiler string, includePaths []string) []string {
+	paths := append([]string(nil), includePaths...)
+	dir := filepath.Dir(compiler)
+	for _, candidate := range []string{filepath.Join(dir, "include"), filepath.Join(dir, "..", "include")} {
+		if info, err := os.Stat(candidate); err == nil && info.IsDir() {
+			paths = append(paths, candidate)
+		}
+	}
+	return paths
+}
+
+// IncludeGraph returns target plus every file it reaches through #include
+// and #tryinclude, resolved the way pawncc does: quoted includes relative to
+// the including file first, then each include path in order
+func IncludeGraph(target string, includePaths []string) []string {
+	files, _ := includeGraph(target, includePaths)
+	return files
+}
+
+// includeGraph is IncludeGraph that also returns every candidate path
+// pawncc looked for and did not find: all of them for an include that does
+// not resolve, and those searched before the match for one that does
+func includeGraph(target string, includePaths []string) ([]string, []string) {
+	visited := make(map[string]bool)
+	var order, absent []string
+	missing := make(map[string]bool)
+
+	var walk func(path string)
+	walk = func(path string) {
+		path = filepath.Clean(path)
+		if visited[path] {
+			return
+		}
+		visited[path] = true
+		order = append(order, path)
+
+		for _, inc := range scanIncludes(path) {
+			for _, candidate := range includeCandidates(inc.name, inc.quoted, filepath.Dir(path), includePaths) {
+				if isFile(candidate) {
+					walk(candidate)
+					break
+				}
+				if candidate = filepath.Clean(candidate); !missing[candidate] {
+					missing[candidate] = true
+					absent = append(absent, candidate)
+				}
+			}
+		}
+	}
+
+	walk(target)
+	return order, absent
+}
+
+// SourceGraph returns the include graph of target with the include paths
+// profile builds with, without compiling anything
+func SourceGraph(target string, profile Profile) ([]string, error) {
+	bp, err := ResolveProfile(profile)
+	if err != nil {
+		return nil, err
+	}
+	includePaths := append(buildIncludePaths(bp.Toolchain), bp.Includes...)
+
+	compilerPath := bp.Compiler
+	if compilerPath == "" && bp.Pin != nil {
+		compilerPath, _ = toolchain.Binary(bp.Pin.Version, bp.Pin.SHA256)
+	}
+	if compilerPath == "" {
+		compilerPath = findCompiler(string(bp.Toolchain))
+	}
+	if compilerPath != "" {
+		includePaths = compilerIncludePaths(compilerPath, includePaths)
+	}
+	return IncludeGraph(target, includePaths), nil
+}
+
+type includeRef struct {
+	name   string
+	quoted bool
+}
+
+func scanIncludes(path string) []includeRef {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	var refs []includeRef
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if m := includeDirective.FindStringSubmatch(scanner.Text()); m != nil {
+			refs = append(refs, includeRef{
+				name:   strings.ReplaceAll(strings.TrimSpace(m[2]), "\\", "/"),
+				quoted: m[1] == `"`,
+			})
+		}
+	}
+	return refs
+}
+
+// includeCandidates lists the paths pawncc tries for an include, in order
+func includeCandidates(name string, quoted bool, fromDir string, includePaths []string) []string {
+	var dirs []string
+	if quoted {
+		dirs = append(dirs, fromDir)
+	}
+	dirs = append(dirs, includePaths...)
+
+	var candidates []string
+	for _, dir := range dirs {
+		for _, suffix := range includeSuffixes {
+			candidates = append(candidates, filepath.Join(dir, name+suffix))
+		}
+	}
+	return candidates
+}
+
+func isFile(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
+
+func getFileHash(path string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return ""
+	}
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
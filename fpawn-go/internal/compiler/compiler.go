@@ -1,16 +1,22 @@
 package compiler
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/FerzDevZ/fpawn/internal/core"
+	"github.com/FerzDevZ/fpawn/internal/manifest"
+	"github.com/FerzDevZ/fpawn/internal/report"
+	"github.com/FerzDevZ/fpawn/internal/toolchain"
 )
 
-// Profile represents a compiler profile (qawno or pawno)
+// Profile names a build profile: a built-in toolchain (qawno, pawno),
+// "auto", or a build declared in the project manifest
 type Profile string
 
 const (
@@ -21,28 +27,39 @@ const (
 
 // CompileResult holds the result of a compilation
 type CompileResult struct {
-	Success  bool
-	Output   string
-	AMXPath  string
-	Duration float64
-	Errors   []string
-	Warnings []string
+	Target      string            `json:"target"`
+	Profile     Profile           `json:"profile"`
+	Success     bool              `json:"success"`
+	Skipped     bool              `json:"skipped"`
+	Cancelled   bool              `json:"cancelled,omitempty"`
+	Output      string            `json:"output"`
+	AMXPath     string            `json:"amx_path"`
+	Duration    float64           `json:"duration"`
+	AMXSize     int64             `json:"amx_size"`
+	Stats       *AMXStats         `json:"stats,omitempty"`
+	Errors      []string          `json:"errors"`
+	Warnings    []string          `json:"warnings"`
+	Diagnostics []Diagnostic      `json:"diagnostics"`
+	Summary     DiagnosticSummary `json:"summary"`
 }
 
 // DetectProfile automatically detects which compiler profile to use
 func DetectProfile() Profile {
+	// Check the manifest preset, runtime and dependencies
+	if m := manifest.Current(); m != nil {
+		if m.IsOpenMP() {
+			return ProfileQawno
+		}
+		if strings.EqualFold(m.Preset, "samp") {
+			return ProfilePawno
+		}
+	}
+
 	// Check for open.mp includes
 	if _, err := os.Stat("qawno"); err == nil {
 		return ProfileQawno
 	}
 
-	// Check for pawn.json preference
-	if data, err := os.ReadFile("pawn.json"); err == nil {
-		if strings.Contains(string(data), "open.mp") {
-			return ProfileQawno
-		}
-	}
-
 	// Check source files
 	entries, _ := filepath.Glob("gamemodes/*.pwn")
 	for _, e := range entries {
@@ -64,19 +81,11 @@ func DetectProfile() Profile {
 
 // FindEntryPoint finds the main .pwn file to compile
 func FindEntryPoint() string {
-	// Check pawn.json
-	if data, err := os.ReadFile("pawn.json"); err == nil {
-		// Simple JSON parsing for "entry"
-		content := string(data)
-		if idx := strings.Index(content, `"entry"`); idx != -1 {
-			start := strings.Index(content[idx:], `"`) + idx + 1
-			start = strings.Index(content[start:], `"`) + start + 1
-			end := strings.Index(content[start:], `"`) + start
-			if end > start {
-				entry := content[start:end]
-				if _, err := os.Stat(entry); err == nil {
-					return entry
-				}
+	// Check pawn.json / pawn.yaml
+	if m := manifest.Current(); m != nil {
+		if entry := m.EntryPoint(); entry != "" {
+			if _, err := os.Stat(entry); err == nil {
+				return entry
 			}
 		}
 	}
@@ -96,9 +105,33 @@ func FindEntryPoint() string {
 	return ""
 }
 
-// Compile compiles the given .pwn file
+// Compile compiles the given .pwn file and prints the outcome
 func Compile(target string, profile Profile) *CompileResult {
+	fmt.Printf(" %s %s\n", core.Blue("[Compiler]"), core.Msg("comp_start"))
+	result := Build(target, profile)
+	PrintCompileResult(result)
+	return result
+}
+
+// BuildOptions tweaks a single Build call
+type BuildOptions struct {
+	// OutputDir, when set, receives the AMX instead of the profile output path
+	OutputDir string
+	// Force skips the incremental build cache
+	Force bool
+	// Context, when set, kills pawncc once it is cancelled
+	Context context.Context
+}
+
+// Build compiles the given .pwn file without printing anything
+func Build(target string, profile Profile) *CompileResult {
+	return BuildWithOptions(target, profile, BuildOptions{})
+}
+
+// BuildWithOptions is Build with explicit output and cache control
+func BuildWithOptions(target string, profile Profile, opts BuildOptions) *CompileResult {
 	result := &CompileResult{
+		Profile: profile,
 		Success: false,
 	}
 
@@ -110,25 +143,27 @@ func Compile(target string, profile Profile) *CompileResult {
 		result.Errors = append(result.Errors, core.Msg("entry_err"))
 		return result
 	}
+	result.Target = target
 
-	// Performance optimization: Check if files changed
-	if !CheckChanges() {
-		fmt.Printf(" %s No changes detected in project resources. Skipping build.\n", core.Green("[Skip]"))
-		result.Success = true
+	bp, err := ResolveProfile(profile)
+	if err != nil {
+		result.Errors = append(result.Errors, err.Error())
 		return result
 	}
-
-	if profile == ProfileAuto {
-		profile = DetectProfile()
+	result.Profile = bp.Name
+
+	// Find compiler binary: explicit path, pinned release, then discovery
+	compilerPath := bp.Compiler
+	if compilerPath == "" && bp.Pin != nil {
+		path, err := toolchain.Binary(bp.Pin.Version, bp.Pin.SHA256)
+		if err != nil {
+			result.Errors = append(result.Errors, err.Error())
+			return result
+		}
+		compilerPath = path
 	}
-
-	// Find compiler binary
-	var compilerPath string
-	switch profile {
-	case ProfileQawno:
-		compilerPath = findCompiler("qawno")
-	case ProfilePawno:
-		compilerPath = findCompiler("pawno")
+	if compilerPath == "" {
+		compilerPath = findCompiler(string(bp.Toolchain))
 	}
 
 	if compilerPath == "" {
@@ -136,44 +171,195 @@ func Compile(target string, profile Profile) *CompileResult {
 		return result
 	}
 
-	fmt.Printf(" %s %s\n", core.Blue("[Compiler]"), core.Msg("comp_start"))
-	fmt.Printf(" %s Profile: %s, Target: %s\n", core.Cyan("[Info]"), string(profile), target)
-
 	// Build include paths
-	includePaths := buildIncludePaths(profile)
+	includePaths := append(buildIncludePaths(bp.Toolchain), bp.Includes...)
 
 	// Build command
-	args := []string{target, "-o", strings.TrimSuffix(target, ".pwn") + ".amx"}
+	result.AMXPath = outputPath(target, bp)
+	if opts.OutputDir != "" {
+		result.AMXPath = filepath.Join(opts.OutputDir, filepath.Base(result.AMXPath))
+	}
+	args := []string{target, "-o", result.AMXPath}
 	for _, inc := range includePaths {
 		args = append(args, "-i"+inc)
 	}
-	args = append(args, "-;+", "-(+", "-d3")
+	args = append(args, bp.CompilerFlags()...)
+	if !hasVerbosity(args) {
+		args = append(args, "-v2")
+	}
 
-	cmd := exec.Command(compilerPath, args...)
+	// Performance optimization: skip when nothing in the include graph,
+	// the compiler or the flags changed since the last successful build
+	key := cacheKey(target, bp.Name, compilerPath, args)
+	fp := fingerprint(target, bp.Name, compilerPath, args, includePaths, result.AMXPath)
+	if !opts.Force && upToDate(key, fp) {
+		result.Skipped = true
+		result.Success = true
+		result.AMXSize = fileSize(result.AMXPath)
+		return result
+	}
+
+	// pawncc does not create the output directory itself
+	if dir := filepath.Dir(result.AMXPath); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("cannot create output directory: %v", err))
+			return result
+		}
+	}
+
+	ctx := opts.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	cmd := exec.CommandContext(ctx, compilerPath, args...)
+	cmd.Env = toolchain.Env(compilerPath)
+	cmd.WaitDelay = time.Second
+	startTime := time.Now()
 	output, err := cmd.CombinedOutput()
+	result.Duration = time.Since(startTime).Seconds()
+	if ctx.Err() != nil {
+		result.Cancelled = true
+		result.Errors = append(result.Errors, "build cancelled")
+		return result
+	}
 
 	result.Output = string(output)
-	result.AMXPath = strings.TrimSuffix(target, ".pwn") + ".amx"
+	result.Stats = ParseStats(result.Output)
 
 	// Parse output for errors/warnings
-	lines := strings.Split(result.Output, "\n")
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if strings.Contains(line, "error") {
-			result.Errors = append(result.Errors, line)
-		} else if strings.Contains(line, "warning") {
-			result.Warnings = append(result.Warnings, line)
+	result.Diagnostics, result.Summary = ParseDiagnostics(result.Output)
+	for _, d := range result.Diagnostics {
+		if d.IsError() {
+			result.Errors = append(result.Errors, d.String())
+		} else {
+			result.Warnings = append(result.Warnings, d.String())
 		}
 	}
 
-	if err == nil && len(result.Errors) == 0 {
-		result.Success = true
+	// A crash or unknown flag leaves nothing to parse; keep the failure visible
+	if err != nil && len(result.Errors) == 0 {
+		msg := fmt.Sprintf("pawncc failed: %v", err)
+		if tail := outputTail(result.Output, 5); tail != "" {
+			msg += "\n" + tail
+		}
+		result.Errors = append(result.Errors, msg)
+	}
+
+	result.Success = err == nil && len(result.Errors) == 0 && !result.Summary.Aborted
+	if result.Success {
+		result.AMXSize = fileSize(result.AMXPath)
+		saveFingerprint(key, fp)
+	}
+	return result
+}
+
+// PrintCompileResult renders a compile result for the terminal
+func PrintCompileResult(result *CompileResult) {
+	if result.Skipped {
+		fmt.Printf(" %s No changes detected in project resources. Skipping build.\n", core.Green("[Skip]"))
+		return
+	}
+
+	if result.Target != "" && result.Profile != "" {
+		fmt.Printf(" %s Profile: %s, Target: %s\n", core.Cyan("[Info]"), string(result.Profile), result.Target)
+	}
+
+	if len(result.Diagnostics) == 0 {
+		for _, e := range result.Errors {
+			fmt.Printf("   %s %s\n", core.Red("✗"), e)
+		}
+	}
+	for _, d := range result.Diagnostics {
+		if d.IsError() {
+			fmt.Printf("   %s %s\n", core.Red("✗"), d.String())
+		} else {
+			fmt.Printf("   %s %s\n", core.Yellow("⚠"), d.String())
+		}
+	}
+
+	if result.Success {
 		fmt.Printf(" %s %s\n", core.Green("[Success]"), core.Msg("comp_success"))
+		printBuildStats(result)
 	} else {
 		fmt.Printf(" %s %s\n", core.Red("[Error]"), core.Msg("comp_fail"))
 	}
+}
 
-	return result
+func printBuildStats(result *CompileResult) {
+	line := fmt.Sprintf("%.2fs", result.Duration)
+	if result.AMXSize > 0 {
+		line += ", AMX " + formatBytes(result.AMXSize)
+	}
+	if s := result.Stats; s != nil {
+		line += fmt.Sprintf(" (code %s, data %s, stack/heap %s", formatBytes(int64(s.Code)), formatBytes(int64(s.Data)), formatBytes(int64(s.StackHeap)))
+		if s.EstimatedUsage >= 0 {
+			line += fmt.Sprintf(", est. usage %s", formatBytes(int64(s.EstimatedUsage)))
+		}
+		line += ")"
+	}
+	fmt.Printf(" %s %s\n", core.Cyan("[Stats]"), line)
+}
+
+func fileSize(path string) int64 {
+	if info, err := os.Stat(path); err == nil {
+		return info.Size()
+	}
+	return 0
+}
+
+// outputTail returns the last n non-empty lines of compiler output
+func outputTail(output string, n int) string {
+	var lines []string
+	for _, line := range strings.Split(output, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// hasVerbosity reports whether args already set a -v level
+func hasVerbosity(args []string) bool {
+	for _, a := range args {
+		if strings.HasPrefix(a, "-v") {
+			return true
+		}
+	}
+	return false
+}
+
+// Findings exposes the compiler diagnostics for SARIF output
+func (r *CompileResult) Findings() []report.Finding {
+	var findings []report.Finding
+	for _, d := range r.Diagnostics {
+		level := "warning"
+		if d.IsError() {
+			level = "error"
+		}
+		findings = append(findings, report.Finding{
+			RuleID:    fmt.Sprintf("pawncc.%03d", d.Code),
+			Level:     level,
+			Message:   d.Message,
+			File:      d.File,
+			StartLine: d.LineStart,
+			EndLine:   d.LineEnd,
+		})
+	}
+	return findings
+}
+
+// outputPath returns the AMX path for target, honouring the profile output
+// when target is the manifest entry point
+func outputPath(target string, bp *BuildProfile) string {
+	if bp.Output != "" {
+		if m := manifest.Current(); m != nil && filepath.Clean(target) == filepath.Clean(m.EntryPoint()) {
+			return bp.Output
+		}
+	}
+	return strings.TrimSuffix(target, ".pwn") + ".amx"
 }
 
 func findCompiler(profile string) string {
@@ -190,6 +376,11 @@ func findCompiler(profile string) string {
 		return globalPath
 	}
 
+	// Check the default managed toolchain
+	if path := toolchain.DefaultBinary(); path != "" {
+		return path
+	}
+
 	// Check PATH
 	if path, err := exec.LookPath("pawncc"); err == nil {
 		return path
@@ -0,0 +1,169 @@
+package compiler
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Severity classifies a compiler diagnostic
+type Severity string
+
+const (
+	SeverityFatal   Severity = "fatal"
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Diagnostic is a single message reported by pawncc
+type Diagnostic struct {
+	File      string   `json:"file"`
+	LineStart int      `json:"line_start"`
+	LineEnd   int      `json:"line_end"`
+	Severity  Severity `json:"severity"`
+	Code      int      `json:"code"`
+	Message   string   `json:"message"`
+}
+
+// IsError reports whether the diagnostic fails the build
+func (d Diagnostic) IsError() bool {
+	return d.Severity == SeverityError || d.Severity == SeverityFatal
+}
+
+// String formats the diagnostic the same way pawncc does
+func (d Diagnostic) String() string {
+	location := strconv.Itoa(d.LineStart)
+	if d.LineEnd != d.LineStart {
+		location = fmt.Sprintf("%d -- %d", d.LineStart, d.LineEnd)
+	}
+	kind := string(d.Severity)
+	if d.Severity == SeverityFatal {
+		kind = "fatal error"
+	}
+	return fmt.Sprintf("%s(%s) : %s %03d: %s", d.File, location, kind, d.Code, d.Message)
+}
+
+// DiagnosticSummary holds the trailer pawncc prints after the diagnostics
+type DiagnosticSummary struct {
+	Errors   int  `json:"errors"`
+	Warnings int  `json:"warnings"`
+	Aborted  bool `json:"aborted"`
+}
+
+// AMXStats is the memory layout pawncc reports at verbosity level 2
+type AMXStats struct {
+	Header    int `json:"header"`
+	Code      int `json:"code"`
+	Data      int `json:"data"`
+	StackHeap int `json:"stack_heap"`
+	// EstimatedUsage is the maximum stack/heap use in bytes, or -1 when
+	// pawncc cannot estimate it (recursion)
+	EstimatedUsage int `json:"estimated_usage"`
+	Total          int `json:"total"`
+}
+
+var (
+	// file(line) : error 017: undefined symbol "x"
+	// file(10 -- 14) : warning 203: symbol is never used: "y"
+	// file(3) : fatal error 100: cannot read from file: "z"
+	diagnosticPattern = regexp.MustCompile(`^(.+?)\((\d+)(?:\s*--\s*(\d+))?\)\s*:\s*(fatal error|error|warning)\s+(\d+)\s*:\s*(.*)$`)
+	summaryPattern    = regexp.MustCompile(`^(\d+)\s+(Errors?|Warnings?)\.?$`)
+
+	// Code size:             1234 bytes
+	// Stack/heap size:      16384 bytes; estimated max. usage=40 cells (160 bytes)
+	sizePattern  = regexp.MustCompile(`^(Header|Code|Data|Stack/heap) size:\s*(\d+) bytes|^Total requirements:\s*(\d+) bytes`)
+	usagePattern = regexp.MustCompile(`estimated max\. usage[=:]?\s*(?:\d+ cells \((\d+) bytes\)|(unknown))`)
+)
+
+// ParseStats extracts the size table printed by "pawncc -v2", or nil when
+// the output has none
+func ParseStats(output string) *AMXStats {
+	stats := &AMXStats{EstimatedUsage: -1}
+	found := false
+
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		m := sizePattern.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		found = true
+
+		if m[3] != "" {
+			stats.Total, _ = strconv.Atoi(m[3])
+			continue
+		}
+		n, _ := strconv.Atoi(m[2])
+		switch m[1] {
+		case "Header":
+			stats.Header = n
+		case "Code":
+			stats.Code = n
+		case "Data":
+			stats.Data = n
+		case "Stack/heap":
+			stats.StackHeap = n
+			if u := usagePattern.FindStringSubmatch(line); u != nil && u[1] != "" {
+				stats.EstimatedUsage, _ = strconv.Atoi(u[1])
+			}
+		}
+	}
+
+	if !found {
+		return nil
+	}
+	return stats
+}
+
+// ParseDiagnostics extracts structured diagnostics from pawncc output
+func ParseDiagnostics(output string) ([]Diagnostic, DiagnosticSummary) {
+	var diags []Diagnostic
+	var summary DiagnosticSummary
+
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		if m := diagnosticPattern.FindStringSubmatch(line); m != nil {
+			d := Diagnostic{
+				File:    strings.TrimSpace(m[1]),
+				Message: strings.TrimSpace(m[6]),
+			}
+			d.LineStart, _ = strconv.Atoi(m[2])
+			d.LineEnd = d.LineStart
+			if m[3] != "" {
+				d.LineEnd, _ = strconv.Atoi(m[3])
+			}
+			d.Code, _ = strconv.Atoi(m[5])
+			switch m[4] {
+			case "fatal error":
+				d.Severity = SeverityFatal
+			case "error":
+				d.Severity = SeverityError
+			default:
+				d.Severity = SeverityWarning
+			}
+			diags = append(diags, d)
+			continue
+		}
+
+		if m := summaryPattern.FindStringSubmatch(line); m != nil {
+			n, _ := strconv.Atoi(m[1])
+			if strings.HasPrefix(m[2], "Error") {
+				summary.Errors = n
+			} else {
+				summary.Warnings = n
+			}
+			continue
+		}
+
+		if strings.HasPrefix(line, "Compilation aborted") {
+			summary.Aborted = true
+		}
+	}
+
+	return diags, summary
+}
@@ -1,118 +1,156 @@
 package compiler
 
 import (
-	"crypto/sha256"
 	"fmt"
-	"io"
-	"os"
 	"path/filepath"
-	"strings"
 	"time"
 
 	"github.com/FerzDevZ/fpawn/internal/core"
+	"github.com/FerzDevZ/fpawn/internal/report"
 )
 
+// MatrixEntry is the outcome of one profile in a matrix build
+type MatrixEntry struct {
+	Profile      Profile        `json:"profile"`
+	Result       *CompileResult `json:"result"`
+	Duration     time.Duration  `json:"duration_ns"`
+	AMXSize      int64          `json:"amx_size"`
+	WarningDelta int            `json:"warning_delta"`
+}
+
 // MatrixResult holds multiple compilation results
 type MatrixResult struct {
-	Legacy   *CompileResult
-	Modern   *CompileResult
-	Duration time.Duration
+	Entries  []MatrixEntry `json:"entries"`
+	Workers  int           `json:"workers"`
+	Duration time.Duration `json:"duration_ns"`
 }
 
-// HybridMatrixBuild runs compilation against multiple profiles
-func HybridMatrixBuild(target string) *MatrixResult {
+// matrixOutputRoot holds one artifact directory per profile
+var matrixOutputRoot = filepath.Join(".fpawn", "matrix")
+
+// HybridMatrixBuild runs compilation against every profile the project declares
+func HybridMatrixBuild(target string, workers int) *MatrixResult {
 	fmt.Printf("\n %s %s\n", core.LBlue("🏗️"), core.Bold("Hybrid Matrix Build"))
 	fmt.Println(" ──────────────────────────────────────────────────")
 
-	startTime := time.Now()
-	result := &MatrixResult{}
+	profiles := ProjectProfiles()
+	fmt.Printf(" %s Building %d profile(s) with %d worker(s)...\n\n", core.Cyan("[Matrix]"), len(profiles), poolWorkers(workers, len(profiles)))
 
-	// Run Legacy (Pawno) Build
-	fmt.Printf(" %s Matrix Phase 1: Legacy Compatibility (Pawno)...\n", core.Cyan("[Matrix]"))
-	result.Legacy = Compile(target, ProfilePawno)
+	result := RunMatrix(target, workers, func(e MatrixEntry) {
+		mark := core.Green("✓")
+		if !e.Result.Success {
+			mark = core.Red("✗")
+		}
+		fmt.Printf("   %s %s finished in %.2fs\n", mark, e.Profile, e.Duration.Seconds())
+	})
 
-	// Run Modern (Qawno/OMP) Build
-	fmt.Printf("\n %s Matrix Phase 2: Modernization Check (Qawno)...\n", core.Cyan("[Matrix]"))
-	result.Modern = Compile(target, ProfileQawno)
+	PrintMatrixSummary(result)
+	return result
+}
+
+// RunMatrix builds every project profile concurrently with at most workers
+// builds in flight (0 means one per CPU). Each profile writes its AMX to
+// .fpawn/matrix/<profile>/ so builds never clobber each other. done, when
+// non-nil, is called once per profile as it finishes, never concurrently.
+func RunMatrix(target string, workers int, done func(MatrixEntry)) *MatrixResult {
+	if target == "" {
+		target = FindEntryPoint()
+	}
+
+	profiles := ProjectProfiles()
+	workers = poolWorkers(workers, len(profiles))
+	result := &MatrixResult{
+		Entries: make([]MatrixEntry, len(profiles)),
+		Workers: workers,
+	}
+
+	startTime := time.Now()
+	runPool(len(profiles), workers, func(i int) {
+		result.Entries[i] = buildMatrixEntry(target, profiles[i].Name)
+	}, func(i int) {
+		if done != nil {
+			done(result.Entries[i])
+		}
+	})
 
 	result.Duration = time.Since(startTime)
 
-	fmt.Println("\n ──────────────────────────────────────────────────")
-	fmt.Printf(" %s Matrix Summary:\n", core.Bold("Results:"))
-	
-	status(result.Legacy, "Legacy")
-	status(result.Modern, "Modern")
-	
-	fmt.Printf("\n %s Total Matrix Time: %v\n", core.LBlue("🕒"), result.Duration)
+	// Warning deltas are relative to the first (baseline) profile
+	if len(result.Entries) > 0 {
+		baseline := len(result.Entries[0].Result.Warnings)
+		for i := range result.Entries {
+			result.Entries[i].WarningDelta = len(result.Entries[i].Result.Warnings) - baseline
+		}
+	}
 
 	return result
 }
 
-func status(res *CompileResult, name string) {
-	if res.Success {
-		fmt.Printf("   %s %-10s: %s\n", core.Green("✓"), name, "SUCCESS")
-	} else {
-		fmt.Printf("   %s %-10s: %s (%d errors)\n", core.Red("✗"), name, "FAILED", len(res.Errors))
+func buildMatrixEntry(target string, profile Profile) MatrixEntry {
+	start := time.Now()
+	res := BuildWithOptions(target, profile, BuildOptions{
+		OutputDir: filepath.Join(matrixOutputRoot, string(profile)),
+		Force:     true,
+	})
+	return MatrixEntry{
+		Profile:  profile,
+		Result:   res,
+		Duration: time.Since(start),
+		AMXSize:  res.AMXSize,
 	}
 }
 
-// IncrementalCache manages compilation offsets
-type IncrementalCache struct {
-	Hashes map[string]string
-}
+// PrintMatrixSummary renders the per-profile comparison table
+func PrintMatrixSummary(result *MatrixResult) {
+	fmt.Println("\n ──────────────────────────────────────────────────")
+	fmt.Printf(" %s Matrix Summary:\n\n", core.Bold("Results:"))
+	fmt.Printf("   %-16s %-8s %8s %6s %6s %10s\n", "PROFILE", "STATUS", "TIME", "WARN", "ΔWARN", "AMX SIZE")
 
-// CheckChanges returns true if any file in the project has changed
-func CheckChanges() bool {
-	cacheFile := filepath.Join(".fpawn", "build_cache.hash")
-	os.MkdirAll(".fpawn", 0755)
-
-	oldHashes := make(map[string]string)
-	if data, err := os.ReadFile(cacheFile); err == nil {
-		lines := strings.Split(string(data), "\n")
-		for _, line := range lines {
-			parts := strings.Split(line, ":")
-			if len(parts) == 2 {
-				oldHashes[parts[0]] = parts[1]
-			}
+	for i, e := range result.Entries {
+		status := core.Green(fmt.Sprintf("%-8s", "OK"))
+		if !e.Result.Success {
+			status = core.Red(fmt.Sprintf("%-8s", "FAILED"))
 		}
+
+		delta := "-"
+		if i > 0 {
+			delta = fmt.Sprintf("%+d", e.WarningDelta)
+		}
+
+		size := "-"
+		if e.AMXSize > 0 {
+			size = formatBytes(e.AMXSize)
+		}
+
+		fmt.Printf("   %-16s %s %7.2fs %6d %6s %10s\n",
+			e.Profile, status, e.Duration.Seconds(), len(e.Result.Warnings), delta, size)
 	}
 
-	newHashes := ""
-	changed := false
-
-	// Scan common dirs
-	dirs := []string{"gamemodes", "include", "filterscripts"}
-	for _, dir := range dirs {
-		filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
-			if err != nil || info.IsDir() {
-				return nil
-			}
-			ext := filepath.Ext(path)
-			if ext == ".pwn" || ext == ".inc" {
-				hash := getFileHash(path)
-				newHashes += fmt.Sprintf("%s:%s\n", path, hash)
-				if oldHashes[path] != hash {
-					changed = true
-				}
-			}
-			return nil
-		})
+	for _, e := range result.Entries {
+		if !e.Result.Success && len(e.Result.Errors) > 0 {
+			fmt.Printf("\n   %s %s: %s\n", core.Red("✗"), e.Profile, e.Result.Errors[0])
+		}
 	}
 
-	os.WriteFile(cacheFile, []byte(newHashes), 0644)
-	return changed
+	fmt.Printf("\n %s Artifacts: %s/<profile>/\n", core.Cyan("[Info]"), matrixOutputRoot)
+	fmt.Printf(" %s Total Matrix Time: %v\n", core.LBlue("🕒"), result.Duration)
 }
 
-func getFileHash(path string) string {
-	f, err := os.Open(path)
-	if err != nil {
-		return ""
+// Findings merges the diagnostics of every profile for SARIF output
+func (m *MatrixResult) Findings() []report.Finding {
+	var findings []report.Finding
+	for _, e := range m.Entries {
+		findings = append(findings, e.Result.Findings()...)
 	}
-	defer f.Close()
+	return findings
+}
 
-	h := sha256.New()
-	if _, err := io.Copy(h, f); err != nil {
-		return ""
+func formatBytes(n int64) string {
+	switch {
+	case n >= 1024*1024:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1024*1024))
+	case n >= 1024:
+		return fmt.Sprintf("%.1f KB", float64(n)/1024)
 	}
-	return fmt.Sprintf("%x", h.Sum(nil))
+	return fmt.Sprintf("%d B", n)
 }
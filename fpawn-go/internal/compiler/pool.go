@@ -0,0 +1,49 @@
+package compiler
+
+import (
+	"runtime"
+	"sync"
+)
+
+// runPool calls job(i) for every i in [0, n) with at most workers calls in
+// flight. done, when non-nil, is called after each job and never concurrently.
+func runPool(n, workers int, job func(i int), done func(i int)) {
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+
+	for w := 0; w < poolWorkers(workers, n); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				job(i)
+				if done != nil {
+					mu.Lock()
+					done(i)
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+// poolWorkers clamps the requested worker count to [1, n], treating 0 as one per CPU
+func poolWorkers(workers, n int) int {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	if workers > n {
+		workers = n
+	}
+	if workers < 1 {
+		workers = 1
+	}
+	return workers
+}
@@ -0,0 +1,161 @@
+package compiler
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/FerzDevZ/fpawn/internal/core"
+	"github.com/FerzDevZ/fpawn/internal/manifest"
+)
+
+// BuildProfile is a fully resolved set of compiler settings
+type BuildProfile struct {
+	Name      Profile           `json:"name"`
+	Toolchain Profile           `json:"toolchain"`
+	Compiler  string            `json:"compiler,omitempty"`
+	Includes  []string          `json:"includes,omitempty"`
+	Constants map[string]string `json:"constants,omitempty"`
+	Args      []string          `json:"args,omitempty"`
+	Debug     *int              `json:"debug,omitempty"`
+	Output    string            `json:"output,omitempty"`
+	// Pin selects a pawncc release installed with "fpawn toolchain install"
+	Pin *manifest.CompilerPin `json:"pin,omitempty"`
+}
+
+var debugFlagPattern = regexp.MustCompile(`^-d\d$`)
+
+// ResolveProfile turns a profile name into compiler settings.
+//
+// "auto" picks the first build declared in the manifest, or the detected
+// toolchain when there is none. "qawno" and "pawno" are always available.
+// Any other name must match a build in pawn.json / pawn.yaml.
+func ResolveProfile(name Profile) (*BuildProfile, error) {
+	m := manifest.Current()
+
+	switch name {
+	case ProfileQawno, ProfilePawno:
+		p := &BuildProfile{Name: name, Toolchain: name}
+		if m != nil {
+			p.Pin = m.CompilerFor(nil)
+		}
+		return p, nil
+	case ProfileAuto, "":
+		if m != nil {
+			if b := m.DefaultBuild(); b != nil {
+				return fromManifestBuild(m, b), nil
+			}
+		}
+		toolchain := DetectProfile()
+		return &BuildProfile{Name: toolchain, Toolchain: toolchain}, nil
+	}
+
+	if m == nil {
+		return nil, fmt.Errorf("profile '%s' requested but no pawn.json or pawn.yaml found", name)
+	}
+	b := m.FindBuild(string(name))
+	if b == nil {
+		return nil, fmt.Errorf("profile '%s' is not declared in %s (available: %s)", name, m.Path, strings.Join(ProfileNames(), ", "))
+	}
+	return fromManifestBuild(m, b), nil
+}
+
+// ProjectProfiles returns every profile the manifest declares, or the
+// legacy pawno/qawno pair when the project has no named builds
+func ProjectProfiles() []*BuildProfile {
+	var profiles []*BuildProfile
+	if m := manifest.Current(); m != nil {
+		for _, b := range m.AllBuilds() {
+			b := b
+			profiles = append(profiles, fromManifestBuild(m, &b))
+		}
+	}
+	if len(profiles) == 0 {
+		profiles = []*BuildProfile{
+			{Name: ProfilePawno, Toolchain: ProfilePawno},
+			{Name: ProfileQawno, Toolchain: ProfileQawno},
+		}
+	}
+	return profiles
+}
+
+// ProfileNames lists the names of the project's profiles
+func ProfileNames() []string {
+	var names []string
+	for _, p := range ProjectProfiles() {
+		names = append(names, string(p.Name))
+	}
+	return names
+}
+
+func fromManifestBuild(m *manifest.Manifest, b *manifest.Build) *BuildProfile {
+	p := &BuildProfile{
+		Name:      Profile(b.Name),
+		Toolchain: Profile(strings.ToLower(b.Toolchain)),
+		Compiler:  b.CompilerPath,
+		Includes:  append([]string(nil), b.Includes...),
+		Constants: b.Constants,
+		Args:      b.Args,
+		Debug:     b.Debug,
+		Output:    b.Output,
+		Pin:       m.CompilerFor(b),
+	}
+	if p.Name == "" {
+		p.Name = "default"
+	}
+	if p.Toolchain != ProfileQawno && p.Toolchain != ProfilePawno {
+		p.Toolchain = DetectProfile()
+	}
+	if b.WorkingDir != "" {
+		for i, inc := range p.Includes {
+			if !filepath.IsAbs(inc) {
+				p.Includes[i] = filepath.Join(b.WorkingDir, inc)
+			}
+		}
+	}
+	if p.Output == "" && m.Output != "" {
+		p.Output = m.Output
+	}
+	return p
+}
+
+// CompilerFlags returns the pawncc switches for the profile: the manifest
+// args when given, otherwise the defaults plus Config.BuildFlags and
+// Config.Optimization. A profile debug level always wins over other -d flags.
+func (p *BuildProfile) CompilerFlags() []string {
+	var flags []string
+	if len(p.Args) > 0 {
+		flags = append(flags, p.Args...)
+	} else {
+		flags = append(flags, "-;+", "-(+")
+		if core.AppConfig != nil {
+			flags = append(flags, strings.Fields(core.AppConfig.BuildFlags)...)
+			flags = append(flags, fmt.Sprintf("-O%d", core.AppConfig.Optimization))
+		} else {
+			flags = append(flags, "-d3")
+		}
+	}
+
+	if p.Debug != nil {
+		kept := flags[:0]
+		for _, f := range flags {
+			if !debugFlagPattern.MatchString(f) {
+				kept = append(kept, f)
+			}
+		}
+		flags = append(kept, fmt.Sprintf("-d%d", *p.Debug))
+	}
+
+	names := make([]string, 0, len(p.Constants))
+	for name := range p.Constants {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		flags = append(flags, name+"="+p.Constants[name])
+	}
+
+	return flags
+}
@@ -85,55 +85,6 @@ func downloadLibrary(url, dest string) error {
 	return err
 }
 
-// Benchmark performs compilation benchmark
-func Benchmark(target string, iterations int) {
-	if target == "" {
-		target = FindEntryPoint()
-	}
-
-	fmt.Printf("\n %s %s\n", core.LBlue("⚡"), core.Bold("Compilation Benchmark"))
-	fmt.Println(" ──────────────────────────────────────────────────")
-
-	if target == "" {
-		fmt.Printf(" %s No target file found\n", core.Red("[Error]"))
-		return
-	}
-
-	if iterations < 1 {
-		iterations = 5
-	}
-
-	fmt.Printf(" Target: %s\n", target)
-	fmt.Printf(" Iterations: %d\n\n", iterations)
-
-	var totalDuration float64
-	successCount := 0
-
-	for i := 1; i <= iterations; i++ {
-		fmt.Printf(" [%d/%d] Compiling... ", i, iterations)
-
-		result := Compile(target, ProfileAuto)
-
-		if result.Success {
-			fmt.Printf("%s (%.2fs)\n", core.Green("OK"), result.Duration)
-			totalDuration += result.Duration
-			successCount++
-		} else {
-			fmt.Printf("%s\n", core.Red("FAIL"))
-		}
-	}
-
-	fmt.Println(" ──────────────────────────────────────────────────")
-
-	if successCount > 0 {
-		avgDuration := totalDuration / float64(successCount)
-		fmt.Printf(" Average compile time: %.3fs\n", avgDuration)
-		fmt.Printf(" Success rate: %d/%d (%.1f%%)\n", successCount, iterations, float64(successCount)/float64(iterations)*100)
-	} else {
-		fmt.Printf(" %s All compilations failed\n", core.Red("✗"))
-	}
-}
-
 // LegacyMatrixBuild compiles for all profiles
 func LegacyMatrixBuild(target string) {
 	if target == "" {
@@ -148,24 +99,25 @@ func LegacyMatrixBuild(target string) {
 		return
 	}
 
-	profiles := []Profile{ProfileQawno, ProfilePawno}
-	successCount := 0
+	result := RunMatrix(target, 0, func(e MatrixEntry) {
+		if e.Result.Success {
+			fmt.Printf(" [%s] Building... %s\n", string(e.Profile), core.Green("OK"))
+		} else {
+			fmt.Printf(" [%s] Building... %s\n", string(e.Profile), core.Red("FAIL"))
+		}
+	})
 
-	for _, profile := range profiles {
-		fmt.Printf(" [%s] Building... ", string(profile))
-		result := Compile(target, profile)
-		if result.Success {
-			fmt.Printf("%s\n", core.Green("OK"))
+	successCount := 0
+	for _, e := range result.Entries {
+		if e.Result.Success {
 			successCount++
-		} else {
-			fmt.Printf("%s\n", core.Red("FAIL"))
 		}
 	}
 
 	fmt.Println(" ──────────────────────────────────────────────────")
-	if successCount == len(profiles) {
+	if successCount == len(result.Entries) {
 		fmt.Printf(" %s All profiles compiled successfully!\n", core.Green("✓"))
 	} else {
-		fmt.Printf(" %s %d/%d profiles succeeded\n", core.Yellow("⚠"), successCount, len(profiles))
+		fmt.Printf(" %s %d/%d profiles succeeded\n", core.Yellow("⚠"), successCount, len(result.Entries))
 	}
 }
@@ -0,0 +1,239 @@
+package compiler
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/FerzDevZ/fpawn/internal/core"
+	"github.com/FerzDevZ/fpawn/internal/manifest"
+	"github.com/FerzDevZ/fpawn/internal/report"
+	"github.com/FerzDevZ/fpawn/internal/servercfg"
+)
+
+// Target kinds, named after the server directory the script is loaded from
+const (
+	KindGamemode     = "gamemode"
+	KindFilterscript = "filterscript"
+	KindNPCMode      = "npcmode"
+)
+
+// BuildTarget is a single script discovered in the project
+type BuildTarget struct {
+	Kind   string `json:"kind"`
+	Source string `json:"source"`
+}
+
+// BuildAllEntry is the outcome of one target in a multi-target build
+type BuildAllEntry struct {
+	BuildTarget
+	Result   *CompileResult `json:"result"`
+	Duration time.Duration  `json:"duration_ns"`
+}
+
+// BuildAllResult holds every target built by --build-all
+type BuildAllResult struct {
+	Profile  Profile         `json:"profile"`
+	Entries  []BuildAllEntry `json:"entries"`
+	Missing  []string        `json:"missing"`
+	Workers  int             `json:"workers"`
+	Failed   int             `json:"failed"`
+	Duration time.Duration   `json:"duration_ns"`
+}
+
+// DiscoverTargets lists the scripts to build. Gamemodes and filterscripts
+// come from the manifest runtime and server.cfg/config.json when either
+// declares them, otherwise from gamemodes/ and filterscripts/. npcmodes/ is
+// always scanned.
+// Declared scripts without a .pwn source (prebuilt AMX) are returned as missing.
+func DiscoverTargets() (targets []BuildTarget, missing []string) {
+	seen := make(map[string]bool)
+	add := func(kind, source string) {
+		source = filepath.Clean(source)
+		if seen[source] {
+			return
+		}
+		seen[source] = true
+		targets = append(targets, BuildTarget{Kind: kind, Source: source})
+	}
+	declare := func(kind, dir, name string) {
+		name = strings.TrimSuffix(strings.TrimSuffix(name, ".amx"), ".pwn")
+		source := filepath.Join(dir, name+".pwn")
+		if _, err := os.Stat(source); err != nil {
+			missing = append(missing, source)
+			return
+		}
+		add(kind, source)
+	}
+
+	gamemodes, filterscripts := declaredScripts()
+	if m := manifest.Current(); m != nil {
+		if entry := m.EntryPoint(); entry != "" {
+			if _, err := os.Stat(entry); err == nil {
+				add(KindGamemode, entry)
+			}
+		}
+	}
+
+	if len(gamemodes) > 0 || len(filterscripts) > 0 {
+		for _, name := range gamemodes {
+			declare(KindGamemode, "gamemodes", name)
+		}
+		for _, name := range filterscripts {
+			declare(KindFilterscript, "filterscripts", name)
+		}
+	} else {
+		for _, source := range globSources("gamemodes") {
+			add(KindGamemode, source)
+		}
+		for _, source := range globSources("filterscripts") {
+			add(KindFilterscript, source)
+		}
+	}
+
+	for _, source := range globSources("npcmodes") {
+		add(KindNPCMode, source)
+	}
+
+	// A bare project with only top-level sources still has something to build
+	if len(targets) == 0 {
+		if entry := FindEntryPoint(); entry != "" {
+			add(KindGamemode, entry)
+		}
+	}
+
+	return targets, missing
+}
+
+// declaredScripts merges the manifest runtime lists with the gamemodes and
+// filterscripts of server.cfg or config.json, manifest first
+func declaredScripts() (gamemodes, filterscripts []string) {
+	if m := manifest.Current(); m != nil {
+		if rt := m.MainRuntime(); rt != nil {
+			gamemodes = append(gamemodes, rt.Gamemodes...)
+			filterscripts = append(filterscripts, rt.Filterscripts...)
+		}
+	}
+
+	if cfg, err := servercfg.Open(); err == nil {
+		gamemodes = append(gamemodes, cfg.Gamemodes()...)
+		filterscripts = append(filterscripts, cfg.Filterscripts()...)
+	}
+	return gamemodes, filterscripts
+}
+
+func globSources(dir string) []string {
+	matches, _ := filepath.Glob(filepath.Join(dir, "*.pwn"))
+	return matches
+}
+
+// BuildAll compiles every discovered gamemode, filterscript and npcmode
+func BuildAll(profile Profile, workers int) *BuildAllResult {
+	fmt.Printf("\n %s %s\n", core.LBlue("🏗️"), core.Bold("Multi-Target Build"))
+	fmt.Println(" ──────────────────────────────────────────────────")
+
+	result := RunBuildAll(profile, workers, func(e BuildAllEntry) {
+		mark := core.Green("✓")
+		switch {
+		case !e.Result.Success:
+			mark = core.Red("✗")
+		case e.Result.Skipped:
+			mark = core.Cyan("•")
+		}
+		fmt.Printf("   %s %s finished in %.2fs\n", mark, e.Source, e.Duration.Seconds())
+	})
+
+	PrintBuildAllSummary(result)
+	return result
+}
+
+// RunBuildAll discovers the project's scripts and compiles them concurrently
+// with at most workers builds in flight (0 means one per CPU). Each target
+// keeps its own incremental cache entry. done, when non-nil, is called once
+// per target as it finishes, never concurrently.
+func RunBuildAll(profile Profile, workers int, done func(BuildAllEntry)) *BuildAllResult {
+	targets, missing := DiscoverTargets()
+	result := &BuildAllResult{
+		Profile: profile,
+		Entries: make([]BuildAllEntry, len(targets)),
+		Missing: missing,
+		Workers: poolWorkers(workers, len(targets)),
+	}
+
+	startTime := time.Now()
+	runPool(len(targets), result.Workers, func(i int) {
+		start := time.Now()
+		result.Entries[i] = BuildAllEntry{
+			BuildTarget: targets[i],
+			Result:      Build(targets[i].Source, profile),
+			Duration:    time.Since(start),
+		}
+	}, func(i int) {
+		if done != nil {
+			done(result.Entries[i])
+		}
+	})
+	result.Duration = time.Since(startTime)
+
+	for _, e := range result.Entries {
+		if !e.Result.Success {
+			result.Failed++
+		}
+	}
+	return result
+}
+
+// PrintBuildAllSummary renders the per-target table and totals
+func PrintBuildAllSummary(result *BuildAllResult) {
+	if len(result.Entries) == 0 {
+		fmt.Printf(" %s %s\n", core.Red("[Error]"), core.Msg("entry_err"))
+		return
+	}
+
+	fmt.Println("\n ──────────────────────────────────────────────────")
+	fmt.Printf(" %s Build Summary:\n\n", core.Bold("Results:"))
+	fmt.Printf("   %-32s %-13s %-8s %8s %6s %6s\n", "TARGET", "KIND", "STATUS", "TIME", "ERR", "WARN")
+
+	for _, e := range result.Entries {
+		status := core.Green(fmt.Sprintf("%-8s", "OK"))
+		switch {
+		case !e.Result.Success:
+			status = core.Red(fmt.Sprintf("%-8s", "FAILED"))
+		case e.Result.Skipped:
+			status = core.Cyan(fmt.Sprintf("%-8s", "CACHED"))
+		}
+		fmt.Printf("   %-32s %-13s %s %7.2fs %6d %6d\n",
+			e.Source, e.Kind, status, e.Duration.Seconds(), len(e.Result.Errors), len(e.Result.Warnings))
+	}
+
+	for _, e := range result.Entries {
+		if !e.Result.Success && len(e.Result.Errors) > 0 {
+			fmt.Printf("\n   %s %s: %s\n", core.Red("✗"), e.Source, e.Result.Errors[0])
+		}
+	}
+	if len(result.Missing) > 0 {
+		fmt.Println()
+	}
+	for _, path := range result.Missing {
+		fmt.Printf("   %s %s is declared but has no source, skipped\n", core.Yellow("⚠"), path)
+	}
+
+	fmt.Println()
+	if result.Failed > 0 {
+		fmt.Printf(" %s %d of %d target(s) failed\n", core.Red("[Error]"), result.Failed, len(result.Entries))
+	} else {
+		fmt.Printf(" %s %d target(s) built\n", core.Green("[Success]"), len(result.Entries))
+	}
+	fmt.Printf(" %s Total Build Time: %v (%d worker(s))\n", core.LBlue("🕒"), result.Duration, result.Workers)
+}
+
+// Findings merges the diagnostics of every target for SARIF output
+func (r *BuildAllResult) Findings() []report.Finding {
+	var findings []report.Finding
+	for _, e := range r.Entries {
+		findings = append(findings, e.Result.Findings()...)
+	}
+	return findings
+}
@@ -1,139 +1,278 @@
 package compiler
 
 import (
+	"context"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/FerzDevZ/fpawn/internal/core"
+	"github.com/FerzDevZ/fpawn/internal/server"
 	"github.com/fsnotify/fsnotify"
 )
 
-// WatchMode starts watching for file changes and recompiles automatically
-func WatchMode(target string) error {
+// WatchEventKind classifies what a Watcher reports
+type WatchEventKind string
+
+const (
+	WatchBuilding  WatchEventKind = "building"
+	WatchResult    WatchEventKind = "result"
+	WatchCancelled WatchEventKind = "cancelled"
+	WatchDir       WatchEventKind = "dir"
+	WatchError     WatchEventKind = "error"
+)
+
+// WatchEvent is a single update from a Watcher
+type WatchEvent struct {
+	Kind    WatchEventKind
+	Trigger string
+	Result  *CompileResult
+	Err     error
+}
+
+// Watcher rebuilds a target whenever a .pwn or .inc file changes. Builds are
+// serialized: a change during a build cancels the stale pawncc, and any
+// number of changes inside the debounce window produce a single rebuild.
+type Watcher struct {
+	Target  string
+	Profile Profile
+	Delay   time.Duration
+
+	fs      *fsnotify.Watcher
+	rebuild chan struct{}
+	dirs    atomic.Int64
+}
+
+// NewWatcher watches every directory under the working directory
+func NewWatcher(target string, profile Profile) (*Watcher, error) {
 	if target == "" {
 		target = FindEntryPoint()
 	}
-
 	if target == "" {
-		return fmt.Errorf(core.Msg("entry_err"))
+		return nil, fmt.Errorf("%s", core.Msg("entry_err"))
 	}
 
-	watcher, err := fsnotify.NewWatcher()
+	fsw, err := fsnotify.NewWatcher()
 	if err != nil {
-		return err
+		return nil, err
 	}
-	defer watcher.Close()
 
-	// Watch current directory and subdirectories
-	watchDirs := []string{"."}
-	if _, err := os.Stat("gamemodes"); err == nil {
-		watchDirs = append(watchDirs, "gamemodes")
+	w := &Watcher{
+		Target:  target,
+		Profile: profile,
+		Delay:   watchDelay(),
+		fs:      fsw,
+		rebuild: make(chan struct{}, 1),
 	}
-	if _, err := os.Stat("include"); err == nil {
-		watchDirs = append(watchDirs, "include")
+	if _, err := w.addTree("."); err != nil {
+		fsw.Close()
+		return nil, err
 	}
+	return w, nil
+}
 
-	for _, dir := range watchDirs {
-		err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
-			if err != nil {
-				return nil
-			}
-			if info.IsDir() {
-				return watcher.Add(path)
-			}
-			return nil
-		})
-		if err != nil {
-			return err
-		}
+// Dirs returns the number of directories being watched
+func (w *Watcher) Dirs() int {
+	return int(w.dirs.Load())
+}
+
+// Rebuild requests a build without waiting for a file change
+func (w *Watcher) Rebuild() {
+	select {
+	case w.rebuild <- struct{}{}:
+	default:
 	}
+}
 
-	fmt.Printf("\n %s %s\n", core.LBlue("⚡"), core.Bold("Watch Mode Active"))
-	fmt.Println(" ──────────────────────────────────────────────────")
-	fmt.Printf(" %s %s\n", core.Bold("Target:"), target)
-	fmt.Printf(" %s %s\n", core.Bold("Auto-Ignition:"), boolToStatus(core.AppConfig.AutoIgnite))
-	fmt.Println(" ──────────────────────────────────────────────────")
-	fmt.Printf(" %s Waiting for changes... (Ctrl+C to exit)\n", core.Cyan("⏳"))
+// Close stops watching
+func (w *Watcher) Close() error {
+	return w.fs.Close()
+}
+
+// Run delivers events until ctx is cancelled. It never runs two builds at once.
+func (w *Watcher) Run(ctx context.Context, events chan<- WatchEvent) error {
+	debounce := time.NewTimer(time.Hour)
+	debounce.Stop()
 
-	// Debounce timer
-	var debounceTimer *time.Timer
-	debounceDelay := 500 * time.Millisecond
+	var (
+		cancelBuild context.CancelFunc
+		building    bool
+		pending     bool
+		trigger     string
+		done        = make(chan *CompileResult, 1)
+	)
+
+	send := func(ev WatchEvent) {
+		select {
+		case events <- ev:
+		case <-ctx.Done():
+		}
+	}
+	start := func() {
+		var bctx context.Context
+		bctx, cancelBuild = context.WithCancel(ctx)
+		building, pending = true, false
+		send(WatchEvent{Kind: WatchBuilding, Trigger: trigger})
+		go func() {
+			done <- BuildWithOptions(w.Target, w.Profile, BuildOptions{Context: bctx})
+		}()
+	}
+	schedule := func(name string) {
+		trigger = name
+		debounce.Reset(w.Delay)
+		// Whatever is compiling now is already out of date
+		if building {
+			cancelBuild()
+		}
+	}
 
 	for {
 		select {
-		case event, ok := <-watcher.Events:
+		case <-ctx.Done():
+			if building {
+				cancelBuild()
+				<-done
+			}
+			return nil
+
+		case event, ok := <-w.fs.Events:
 			if !ok {
 				return nil
 			}
-
-			// Only react to .pwn and .inc files
-			ext := filepath.Ext(event.Name)
-			if ext != ".pwn" && ext != ".inc" {
-				continue
+			if event.Op&fsnotify.Create != 0 {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					if sources, err := w.addTree(event.Name); err == nil {
+						send(WatchEvent{Kind: WatchDir, Trigger: event.Name})
+						if sources {
+							schedule(event.Name)
+						}
+					}
+					continue
+				}
 			}
-
-			// Debounce
-			if debounceTimer != nil {
-				debounceTimer.Stop()
+			if isSourceFile(event.Name) && event.Op&fsnotify.Chmod != event.Op {
+				schedule(event.Name)
 			}
 
-			debounceTimer = time.AfterFunc(debounceDelay, func() {
-				fmt.Printf("\n %s %s: %s\n", core.Blue("🔄"), core.Msg("wat_sync"), event.Name)
-
-				result := Compile(target, ProfileAuto)
+		case <-w.rebuild:
+			schedule("manual rebuild")
 
-				if result.Success && core.AppConfig.AutoIgnite {
-					RunServer()
-				}
+		case <-debounce.C:
+			if building {
+				pending = true
+				continue
+			}
+			start()
 
-				fmt.Printf("\n %s Waiting for changes...\n", core.Cyan("⏳"))
-			})
+		case result := <-done:
+			building = false
+			cancelBuild()
+			if result.Cancelled {
+				send(WatchEvent{Kind: WatchCancelled, Trigger: trigger})
+			} else {
+				send(WatchEvent{Kind: WatchResult, Trigger: trigger, Result: result})
+			}
+			if pending {
+				start()
+			}
 
-		case err, ok := <-watcher.Errors:
+		case err, ok := <-w.fs.Errors:
 			if !ok {
 				return nil
 			}
-			fmt.Printf(" %s Watch error: %v\n", core.Red("[Error]"), err)
+			send(WatchEvent{Kind: WatchError, Err: err})
 		}
 	}
 }
 
-func boolToStatus(b bool) string {
-	if b {
-		return core.Green("ON")
-	}
-	return core.Yellow("OFF")
+// addTree watches dir and its subdirectories, skipping hidden ones, and
+// reports whether it holds any Pawn sources
+func (w *Watcher) addTree(dir string) (bool, error) {
+	sources := false
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if !info.IsDir() {
+			if isSourceFile(path) {
+				sources = true
+			}
+			return nil
+		}
+		if path != "." && strings.HasPrefix(info.Name(), ".") {
+			return filepath.SkipDir
+		}
+		if err := w.fs.Add(path); err != nil {
+			return err
+		}
+		w.dirs.Add(1)
+		return nil
+	})
+	return sources, err
 }
 
-// RunServer starts the SA-MP/open.mp server
-func RunServer() error {
-	var binary string
+func isSourceFile(name string) bool {
+	ext := filepath.Ext(name)
+	return ext == ".pwn" || ext == ".inc"
+}
 
-	if _, err := os.Stat("./omp-server"); err == nil {
-		binary = "./omp-server"
-	} else if _, err := os.Stat("./samp03svr"); err == nil {
-		binary = "./samp03svr"
+// watchDelay is Config.WatchDelay, the debounce window in milliseconds
+func watchDelay() time.Duration {
+	if core.AppConfig != nil && core.AppConfig.WatchDelay > 0 {
+		return time.Duration(core.AppConfig.WatchDelay) * time.Millisecond
 	}
+	return 500 * time.Millisecond
+}
 
-	if binary == "" {
-		return fmt.Errorf("server binary not found (omp-server or samp03svr)")
+// WatchMode watches for file changes and recompiles, printing each result
+// as it scrolls by. The dashboard view lives in ui.WatchDashboard.
+func WatchMode(target string) error {
+	w, err := NewWatcher(target, ProfileAuto)
+	if err != nil {
+		return err
 	}
+	defer w.Close()
 
-	// Kill existing server
-	exec.Command("pkill", "-f", binary).Run()
-
-	fmt.Printf(" %s Starting server: %s\n", core.Green("[Server]"), binary)
+	fmt.Printf("\n %s %s\n", core.LBlue("⚡"), core.Bold("Watch Mode Active"))
+	fmt.Println(" ──────────────────────────────────────────────────")
+	fmt.Printf(" %s %s\n", core.Bold("Target:"), w.Target)
+	fmt.Printf(" %s %v\n", core.Bold("Debounce:"), w.Delay)
+	fmt.Printf(" %s %s\n", core.Bold("Auto-Ignition:"), boolToStatus(core.AppConfig.AutoIgnite))
+	fmt.Println(" ──────────────────────────────────────────────────")
+	fmt.Printf(" %s Waiting for changes... (Ctrl+C to exit)\n", core.Cyan("⏳"))
 
-	cmd := exec.Command(binary)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	cmd.Dir = "."
+	events := make(chan WatchEvent)
+	go w.Run(context.Background(), events)
 
-	// Set LD_LIBRARY_PATH
-	cmd.Env = append(os.Environ(), "LD_LIBRARY_PATH=.:"+os.Getenv("LD_LIBRARY_PATH"))
+	for ev := range events {
+		switch ev.Kind {
+		case WatchBuilding:
+			fmt.Printf("\n %s %s: %s\n", core.Blue("🔄"), core.Msg("wat_sync"), ev.Trigger)
+		case WatchCancelled:
+			fmt.Printf(" %s Superseded by a newer change\n", core.Yellow("[Cancel]"))
+		case WatchDir:
+			fmt.Printf(" %s Watching new directory: %s\n", core.Cyan("[Watch]"), ev.Trigger)
+		case WatchError:
+			fmt.Printf(" %s Watch error: %v\n", core.Red("[Error]"), ev.Err)
+		case WatchResult:
+			PrintCompileResult(ev.Result)
+			if ev.Result.Success && core.AppConfig.AutoIgnite {
+				if err := server.RestartServer(); err != nil {
+					fmt.Printf(" %s Auto-Ignition failed: %v\n", core.Red("[Error]"), err)
+				}
+			}
+			fmt.Printf("\n %s Waiting for changes...\n", core.Cyan("⏳"))
+		}
+	}
+	return nil
+}
 
-	return cmd.Start()
+func boolToStatus(b bool) string {
+	if b {
+		return core.Green("ON")
+	}
+	return core.Yellow("OFF")
 }
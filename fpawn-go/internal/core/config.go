@@ -27,6 +27,7 @@ type Config struct {
 	Optimization   int
 	DiscordWebhook string
 	WatchDelay     int
+	Registries     string // extra plugin indexes (URLs or files), highest priority first
 }
 
 var AppConfig *Config
@@ -132,6 +133,8 @@ func parseConfig(data string) {
 			AppConfig.DiscordWebhook = value
 		case "WATCH_DELAY":
 			fmt.Sscanf(value, "%d", &AppConfig.WatchDelay)
+		case "REGISTRIES":
+			AppConfig.Registries = value
 		}
 	}
 }
@@ -162,11 +165,12 @@ GIT_SYNC="%s"
 OPTIMIZATION="%d"
 WEBHOOK="%s"
 WATCH_DELAY="%d"
+REGISTRIES="%s"
 `, AppConfig.RepoOwner, AppConfig.RepoName, AppConfig.Lang, ignite,
 		AppConfig.BuildFlags, AppConfig.Theme, AppConfig.Sensitivity,
 		AppConfig.SshHost, AppConfig.SshUser, AppConfig.SshPath,
 		AppConfig.LogLevel, git, AppConfig.Optimization,
-		AppConfig.DiscordWebhook, AppConfig.WatchDelay)
+		AppConfig.DiscordWebhook, AppConfig.WatchDelay, AppConfig.Registries)
 
 	return os.WriteFile(AppConfig.ConfigFile, []byte(content), 0644)
 }
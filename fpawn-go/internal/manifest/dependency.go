@@ -0,0 +1,77 @@
+package manifest
+
+import "strings"
+
+// Dependency is a parsed sampctl dependency string
+//
+// Accepted forms: user/repo, user/repo/path, and any of those suffixed
+// with :tag, @branch or #commit. A leading https://github.com/ is ignored.
+type Dependency struct {
+	User   string
+	Repo   string
+	Path   string
+	Tag    string
+	Branch string
+	Commit string
+}
+
+// ParseDependency splits a dependency string into its parts
+func ParseDependency(s string) Dependency {
+	var d Dependency
+
+	s = strings.TrimSpace(s)
+	s = strings.TrimPrefix(s, "https://")
+	s = strings.TrimPrefix(s, "http://")
+	s = strings.TrimPrefix(s, "github.com/")
+
+	if i := strings.LastIndex(s, "#"); i != -1 {
+		d.Commit = s[i+1:]
+		s = s[:i]
+	} else if i := strings.LastIndex(s, "@"); i != -1 {
+		d.Branch = s[i+1:]
+		s = s[:i]
+	} else if i := strings.LastIndex(s, ":"); i != -1 {
+		d.Tag = s[i+1:]
+		s = s[:i]
+	}
+
+	parts := strings.SplitN(strings.TrimSuffix(s, ".git"), "/", 3)
+	if len(parts) > 0 {
+		d.User = parts[0]
+	}
+	if len(parts) > 1 {
+		d.Repo = parts[1]
+	}
+	if len(parts) > 2 {
+		d.Path = parts[2]
+	}
+	return d
+}
+
+// Ref returns the tag, branch or commit the dependency pins, if any
+func (d Dependency) Ref() string {
+	switch {
+	case d.Tag != "":
+		return d.Tag
+	case d.Branch != "":
+		return d.Branch
+	}
+	return d.Commit
+}
+
+// String formats the dependency back into sampctl notation
+func (d Dependency) String() string {
+	s := d.User + "/" + d.Repo
+	if d.Path != "" {
+		s += "/" + d.Path
+	}
+	switch {
+	case d.Tag != "":
+		s += ":" + d.Tag
+	case d.Branch != "":
+		s += "@" + d.Branch
+	case d.Commit != "":
+		s += "#" + d.Commit
+	}
+	return s
+}
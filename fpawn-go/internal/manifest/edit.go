@@ -0,0 +1,153 @@
+package manifest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SetField sets a top-level key in the manifest file at path, keeping the
+// order of the other keys (and, for YAML, their comments) intact.
+// A nil value removes the key.
+func SetField(path, key string, value interface{}) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+	if len(doc.Content) == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return fmt.Errorf("%s: top level is not an object", path)
+	}
+
+	var valueNode *yaml.Node
+	if value != nil {
+		valueNode = &yaml.Node{}
+		if err := valueNode.Encode(value); err != nil {
+			return err
+		}
+	}
+
+	found := false
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value != key {
+			continue
+		}
+		found = true
+		if valueNode == nil {
+			root.Content = append(root.Content[:i], root.Content[i+2:]...)
+		} else {
+			root.Content[i+1] = valueNode
+		}
+		break
+	}
+	if !found && valueNode != nil {
+		root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, valueNode)
+	}
+
+	var buf bytes.Buffer
+	switch filepath.Ext(path) {
+	case ".yaml", ".yml":
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(2)
+		if err := enc.Encode(&doc); err != nil {
+			return err
+		}
+		enc.Close()
+	default:
+		writeJSONNode(&buf, root, DetectIndent(data), 0)
+		buf.WriteByte('\n')
+	}
+	return os.WriteFile(path, buf.Bytes(), 0644)
+}
+
+// EncodeJSON renders a node tree parsed from JSON back to JSON with the
+// given indent unit, so other JSON files can be edited like pawn.json
+func EncodeJSON(n *yaml.Node, indent string) []byte {
+	var buf bytes.Buffer
+	writeJSONNode(&buf, n, indent, 0)
+	buf.WriteByte('\n')
+	return buf.Bytes()
+}
+
+// writeJSONNode renders a YAML node tree as JSON; yaml.v3 parses JSON too,
+// so this round-trips pawn.json without reordering keys
+func writeJSONNode(buf *bytes.Buffer, n *yaml.Node, indent string, depth int) {
+	pad := func(d int) { buf.WriteString(strings.Repeat(indent, d)) }
+
+	switch n.Kind {
+	case yaml.DocumentNode:
+		if len(n.Content) > 0 {
+			writeJSONNode(buf, n.Content[0], indent, depth)
+		}
+	case yaml.MappingNode:
+		if len(n.Content) == 0 {
+			buf.WriteString("{}")
+			return
+		}
+		buf.WriteString("{\n")
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			pad(depth + 1)
+			key, _ := json.Marshal(n.Content[i].Value)
+			buf.Write(key)
+			buf.WriteString(": ")
+			writeJSONNode(buf, n.Content[i+1], indent, depth+1)
+			if i+2 < len(n.Content) {
+				buf.WriteByte(',')
+			}
+			buf.WriteByte('\n')
+		}
+		pad(depth)
+		buf.WriteByte('}')
+	case yaml.SequenceNode:
+		if len(n.Content) == 0 {
+			buf.WriteString("[]")
+			return
+		}
+		buf.WriteString("[\n")
+		for i, item := range n.Content {
+			pad(depth + 1)
+			writeJSONNode(buf, item, indent, depth+1)
+			if i+1 < len(n.Content) {
+				buf.WriteByte(',')
+			}
+			buf.WriteByte('\n')
+		}
+		pad(depth)
+		buf.WriteByte(']')
+	case yaml.AliasNode:
+		writeJSONNode(buf, n.Alias, indent, depth)
+	default:
+		switch n.Tag {
+		case "!!int", "!!float", "!!bool", "!!null":
+			buf.WriteString(n.Value)
+		default:
+			value, _ := json.Marshal(n.Value)
+			buf.Write(value)
+		}
+	}
+}
+
+// DetectIndent returns the indentation unit of the first indented line
+func DetectIndent(data []byte) string {
+	for _, line := range strings.Split(string(data), "\n") {
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed == "" || len(trimmed) == len(line) {
+			continue
+		}
+		return line[:len(line)-len(trimmed)]
+	}
+	return "  "
+}
@@ -0,0 +1,238 @@
+package manifest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileNames lists the manifest files recognised, in lookup order
+var FileNames = []string{"pawn.json", "pawn.yaml", "pawn.yml"}
+
+// ErrNotFound is returned when a directory has no manifest
+var ErrNotFound = errors.New("no pawn.json or pawn.yaml found")
+
+// Manifest is a sampctl-compatible package definition
+type Manifest struct {
+	User            string    `json:"user,omitempty" yaml:"user,omitempty"`
+	Repo            string    `json:"repo,omitempty" yaml:"repo,omitempty"`
+	Entry           string    `json:"entry,omitempty" yaml:"entry,omitempty"`
+	Output          string    `json:"output,omitempty" yaml:"output,omitempty"`
+	Preset          string    `json:"preset,omitempty" yaml:"preset,omitempty"`
+	Dependencies    []string  `json:"dependencies,omitempty" yaml:"dependencies,omitempty"`
+	DevDependencies []string  `json:"dev_dependencies,omitempty" yaml:"dev_dependencies,omitempty"`
+	Local           bool      `json:"local,omitempty" yaml:"local,omitempty"`
+	Build           *Build    `json:"build,omitempty" yaml:"build,omitempty"`
+	Builds          []Build   `json:"builds,omitempty" yaml:"builds,omitempty"`
+	Runtime         *Runtime  `json:"runtime,omitempty" yaml:"runtime,omitempty"`
+	Runtimes        []Runtime `json:"runtimes,omitempty" yaml:"runtimes,omitempty"`
+
+	// Compiler pins the pawncc release for every build that does not pin its own
+	Compiler *CompilerPin `json:"compiler,omitempty" yaml:"compiler,omitempty"`
+
+	// Path is the file the manifest was loaded from
+	Path string `json:"-" yaml:"-"`
+}
+
+// Build is a named compiler configuration
+//
+// Toolchain, CompilerPath and Debug are fpawn extensions; sampctl ignores them.
+type Build struct {
+	Name         string            `json:"name,omitempty" yaml:"name,omitempty"`
+	Version      string            `json:"version,omitempty" yaml:"version,omitempty"`
+	WorkingDir   string            `json:"workingDir,omitempty" yaml:"workingDir,omitempty"`
+	Args         []string          `json:"args,omitempty" yaml:"args,omitempty"`
+	Input        string            `json:"input,omitempty" yaml:"input,omitempty"`
+	Output       string            `json:"output,omitempty" yaml:"output,omitempty"`
+	Includes     []string          `json:"includes,omitempty" yaml:"includes,omitempty"`
+	Constants    map[string]string `json:"constants,omitempty" yaml:"constants,omitempty"`
+	Toolchain    string            `json:"toolchain,omitempty" yaml:"toolchain,omitempty"`
+	CompilerPath string            `json:"compiler_path,omitempty" yaml:"compiler_path,omitempty"`
+	Debug        *int              `json:"debug,omitempty" yaml:"debug,omitempty"`
+	Compiler     *CompilerPin      `json:"compiler,omitempty" yaml:"compiler,omitempty"`
+}
+
+// CompilerPin fixes the pawncc release a build uses, as in sampctl's
+// build.compiler. SHA256 is an fpawn extension: the checksum of the
+// release archive, so every machine installs byte-identical compilers.
+type CompilerPin struct {
+	Version string `json:"version,omitempty" yaml:"version,omitempty"`
+	SHA256  string `json:"sha256,omitempty" yaml:"sha256,omitempty"`
+}
+
+// Runtime describes the server the package runs under
+type Runtime struct {
+	Name          string   `json:"name,omitempty" yaml:"name,omitempty"`
+	Version       string   `json:"version,omitempty" yaml:"version,omitempty"`
+	Mode          string   `json:"mode,omitempty" yaml:"mode,omitempty"`
+	Echo          string   `json:"echo,omitempty" yaml:"echo,omitempty"`
+	Port          int      `json:"port,omitempty" yaml:"port,omitempty"`
+	Hostname      string   `json:"hostname,omitempty" yaml:"hostname,omitempty"`
+	RCONPassword  string   `json:"rcon_password,omitempty" yaml:"rcon_password,omitempty"`
+	MaxPlayers    int      `json:"maxplayers,omitempty" yaml:"maxplayers,omitempty"`
+	Language      string   `json:"language,omitempty" yaml:"language,omitempty"`
+	Gamemodes     []string `json:"gamemodes,omitempty" yaml:"gamemodes,omitempty"`
+	Filterscripts []string `json:"filterscripts,omitempty" yaml:"filterscripts,omitempty"`
+	Plugins       []string `json:"plugins,omitempty" yaml:"plugins,omitempty"`
+}
+
+// Load reads the manifest in dir, preferring pawn.json over pawn.yaml
+func Load(dir string) (*Manifest, error) {
+	for _, name := range FileNames {
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); err == nil {
+			return LoadFile(path)
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// LoadFile parses a single manifest file, choosing the decoder by extension
+func LoadFile(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Manifest{Path: path}
+	switch filepath.Ext(path) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, m)
+	default:
+		err = json.Unmarshal(data, m)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+
+	return m, nil
+}
+
+// Current loads the manifest of the working directory, or nil if absent or invalid
+func Current() *Manifest {
+	m, err := Load(".")
+	if err != nil {
+		return nil
+	}
+	return m
+}
+
+// Dir returns the directory containing the manifest
+func (m *Manifest) Dir() string {
+	if m.Path == "" {
+		return "."
+	}
+	return filepath.Dir(m.Path)
+}
+
+// AllBuilds returns the declared builds, including the singular "build" form
+func (m *Manifest) AllBuilds() []Build {
+	var builds []Build
+	if m.Build != nil {
+		builds = append(builds, *m.Build)
+	}
+	return append(builds, m.Builds...)
+}
+
+// FindBuild returns the build with the given name, or nil. "default" also
+// finds an unnamed build, which is how profiles list one.
+func (m *Manifest) FindBuild(name string) *Build {
+	builds := m.AllBuilds()
+	for _, b := range builds {
+		if b.Name == name {
+			return &b
+		}
+	}
+	if name == "default" {
+		for _, b := range builds {
+			if b.Name == "" {
+				return &b
+			}
+		}
+	}
+	return nil
+}
+
+// DefaultBuild returns the first declared build, or nil if there is none
+func (m *Manifest) DefaultBuild() *Build {
+	builds := m.AllBuilds()
+	if len(builds) == 0 {
+		return nil
+	}
+	return &builds[0]
+}
+
+// EntryPoint returns the source to compile, falling back to the default build input
+func (m *Manifest) EntryPoint() string {
+	if m.Entry != "" {
+		return m.Entry
+	}
+	if b := m.DefaultBuild(); b != nil && b.Input != "" {
+		return b.Input
+	}
+	return ""
+}
+
+// OutputPath returns the AMX path the manifest declares for the entry point
+func (m *Manifest) OutputPath() string {
+	if m.Output != "" {
+		return m.Output
+	}
+	if b := m.DefaultBuild(); b != nil && b.Output != "" {
+		return b.Output
+	}
+	return ""
+}
+
+// CompilerFor returns the compiler pin for b, falling back to the
+// project-wide pin; b may be nil
+func (m *Manifest) CompilerFor(b *Build) *CompilerPin {
+	if b != nil && b.Compiler != nil && b.Compiler.Version != "" {
+		return b.Compiler
+	}
+	if m.Compiler != nil && m.Compiler.Version != "" {
+		return m.Compiler
+	}
+	return nil
+}
+
+// MainRuntime returns the runtime section, preferring "runtime" over "runtimes[0]"
+func (m *Manifest) MainRuntime() *Runtime {
+	if m.Runtime != nil {
+		return m.Runtime
+	}
+	if len(m.Runtimes) > 0 {
+		return &m.Runtimes[0]
+	}
+	return nil
+}
+
+// IsOpenMP reports whether the package targets open.mp rather than SA-MP
+func (m *Manifest) IsOpenMP() bool {
+	switch strings.ToLower(m.Preset) {
+	case "openmp", "open.mp", "omp":
+		return true
+	case "samp":
+		return false
+	}
+
+	if rt := m.MainRuntime(); rt != nil {
+		v := strings.ToLower(rt.Version)
+		if strings.Contains(v, "openmp") || strings.Contains(v, "open.mp") || strings.HasPrefix(v, "omp") {
+			return true
+		}
+	}
+
+	for _, dep := range m.Dependencies {
+		d := ParseDependency(dep)
+		if d.User == "openmultiplayer" || strings.Contains(d.Repo, "omp-stdlib") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,183 @@
+package plugins
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// assetKinds are the file types the installer knows how to place
+var assetKinds = []string{".zip", ".tar.gz", ".tgz", ".tar.xz", ".txz", ".so", ".dll", ".inc"}
+
+// osTokens name each server platform in asset names
+var osTokens = map[string][]string{
+	"linux":   {"linux", "ubuntu", "debian", "centos", "lin"},
+	"windows": {"windows", "win", "win32", "win64"},
+}
+
+// findAsset picks the release asset that best fits this machine and the
+// project's ecosystem, or nil when none can be installed here
+func findAsset(assets []Asset) *Asset {
+	eco := DetectEcosystem()
+	// Scores may be negative; only -1 rules an asset out
+	best, bestScore := -1, 0
+	for i, a := range assets {
+		s := scoreAsset(a.Name, runtime.GOOS, eco)
+		if s == -1 {
+			continue
+		}
+		if best < 0 || s > bestScore {
+			best, bestScore = i, s
+		}
+	}
+	if best < 0 {
+		return nil
+	}
+	return &assets[best]
+}
+
+// scoreAsset rates an asset name for goos and ecosystem; -1 means it must
+// not be installed. The server only loads 32-bit plugins, so 64-bit and
+// ARM builds lose to anything else.
+func scoreAsset(name, goos, eco string) int {
+	lower := strings.ToLower(name)
+	kind := assetKind(lower)
+	if kind == "" {
+		return -1
+	}
+	tokens := strings.FieldsFunc(strings.TrimSuffix(lower, kind), func(r rune) bool {
+		return r == '-' || r == '_' || r == '.' || r == ' '
+	})
+	has := func(words ...string) bool {
+		for _, t := range tokens {
+			for _, w := range words {
+				if t == w {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	if has("src", "source", "sources", "debug", "symbols", "pdb") {
+		return -1
+	}
+
+	score := 0
+	switch {
+	case kind == ".so":
+		score += 100
+		if goos == "windows" {
+			return -1
+		}
+	case kind == ".dll":
+		score += 100
+		if goos != "windows" {
+			return -1
+		}
+	case kind == ".inc":
+		// Only worth it when there is no build at all
+		score -= 50
+	default:
+		// Archives usually ship the include next to the binary
+		score += 5
+	}
+
+	for platform, words := range osTokens {
+		if has(words...) {
+			if platform != goos {
+				return -1
+			}
+			score += 100
+		}
+	}
+
+	switch {
+	case has("arm", "arm64", "aarch64", "armhf"):
+		return -1
+	case strings.Contains(lower, "x86_64") || has("x64", "amd64", "64bit", "win64"):
+		score -= 60
+	case has("x86", "i386", "i686", "386", "32", "32bit", "win32"):
+		score += 20
+	}
+
+	omp := strings.Contains(lower, "open.mp") || has("omp", "openmp", "component")
+	legacy := has("samp", "legacy")
+	switch {
+	case omp && eco == EcosystemOpenMP, legacy && eco == EcosystemSAMP:
+		score += 10
+	case omp:
+		// open.mp components do not load in the SA-MP server
+		score -= 80
+	}
+	return score
+}
+
+// assetKind returns the installable extension of name, or ""
+func assetKind(name string) string {
+	for _, kind := range assetKinds {
+		if strings.HasSuffix(name, kind) {
+			return kind
+		}
+	}
+	return ""
+}
+
+// projectIncludeDir is where plugin includes go: the project's own include
+// directory, else the one of the bundled compiler, searched in the order
+// the compiler uses them
+func projectIncludeDir() string {
+	for _, dir := range []string{"include", filepath.Join("qawno", "include"), filepath.Join("pawno", "include")} {
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			return dir
+		}
+	}
+	return "include"
+}
+
+// layoutPath maps a file inside a release archive to where it belongs in
+// the project, or "" to leave it out. Archives lay out their files in
+// many ways (plugins/x.so, pawno/include/x.inc, x-2.0/include/x.inc, a
+// bare x.so); what matters is the kind of file and the directory it sits
+// under. Binaries for other platforms are dropped.
+func layoutPath(name, goos, includeDir string) string {
+	name = path.Clean(strings.ReplaceAll(name, "\\", "/"))
+	if !filepath.IsLocal(name) {
+		return ""
+	}
+	parts := strings.Split(name, "/")
+	base := parts[len(parts)-1]
+	lower := strings.ToLower(base)
+
+	under := func(dir string) int {
+		for i := len(parts) - 2; i >= 0; i-- {
+			if strings.EqualFold(parts[i], dir) {
+				return i
+			}
+		}
+		return -1
+	}
+
+	switch path.Ext(lower) {
+	case ".so", ".dll":
+		if (path.Ext(lower) == ".so") == (goos == "windows") {
+			return ""
+		}
+		if under("components") >= 0 {
+			return path.Join("components", base)
+		}
+		return path.Join("plugins", base)
+	case ".inc":
+		// Keep the layout below include/ so libraries like YSI still resolve
+		if i := under("include"); i >= 0 {
+			return path.Join(append([]string{filepath.ToSlash(includeDir)}, parts[i+1:]...)...)
+		}
+		return path.Join(filepath.ToSlash(includeDir), base)
+	case ".pwn", ".amx":
+		if under("filterscripts") >= 0 {
+			return path.Join("filterscripts", base)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,154 @@
+package plugins
+
+import (
+	"bytes"
+	"debug/elf"
+	"debug/pe"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/FerzDevZ/fpawn/internal/server"
+)
+
+// binArch is the format and target of a server or plugin binary
+type binArch struct {
+	Format  string // "ELF" or "PE", empty when only the target is known
+	Bits    int
+	Machine string
+}
+
+func (a binArch) String() string {
+	s := fmt.Sprintf("%d-bit %s", a.Bits, a.Machine)
+	if a.Format != "" {
+		s = a.Format + " " + s
+	}
+	return s
+}
+
+// readArch reads the ELF or PE header of path
+func readArch(path string) (binArch, error) {
+	if f, err := elf.Open(path); err == nil {
+		defer f.Close()
+		a := binArch{Format: "ELF", Bits: 64, Machine: f.Machine.String()}
+		if f.Class == elf.ELFCLASS32 {
+			a.Bits = 32
+		}
+		switch f.Machine {
+		case elf.EM_386:
+			a.Machine = "x86"
+		case elf.EM_X86_64:
+			a.Machine = "x86-64"
+		case elf.EM_ARM:
+			a.Machine = "arm"
+		case elf.EM_AARCH64:
+			a.Machine = "arm64"
+		}
+		return a, nil
+	}
+
+	f, err := pe.Open(path)
+	if err != nil {
+		return binArch{}, fmt.Errorf("not an ELF or PE binary")
+	}
+	defer f.Close()
+	a := binArch{Format: "PE", Bits: 32, Machine: fmt.Sprintf("machine 0x%x", f.Machine)}
+	if _, ok := f.OptionalHeader.(*pe.OptionalHeader64); ok {
+		a.Bits = 64
+	}
+	switch f.Machine {
+	case pe.IMAGE_FILE_MACHINE_I386:
+		a.Machine = "x86"
+	case pe.IMAGE_FILE_MACHINE_AMD64:
+		a.Machine = "x86-64"
+	case pe.IMAGE_FILE_MACHINE_ARMNT:
+		a.Machine = "arm"
+	case pe.IMAGE_FILE_MACHINE_ARM64:
+		a.Machine = "arm64"
+	}
+	return a, nil
+}
+
+// serverArch is the target plugins must be built for: that of the project's
+// server binary, else 32-bit x86, the only build SA-MP has ever shipped.
+// The second value names the binary it was read from.
+func serverArch() (binArch, string) {
+	if bin, err := server.Binary(); err == nil {
+		if a, err := readArch(bin); err == nil {
+			return binArch{Bits: a.Bits, Machine: a.Machine}, filepath.Base(bin)
+		}
+	}
+	return binArch{Bits: 32, Machine: "x86"}, ""
+}
+
+// checkArch explains why the binary at path cannot be loaded by a server
+// built for want, or returns "" when it can
+func checkArch(path string, a binArch, want binArch) string {
+	switch ext := strings.ToLower(filepath.Ext(path)); {
+	case ext == ".so" && a.Format != "ELF", ext == ".dll" && a.Format != "PE":
+		return fmt.Sprintf("%s binary with a %s name", a.Format, ext)
+	case a.Bits != want.Bits || a.Machine != want.Machine:
+		return fmt.Sprintf("built for %d-bit %s; the server is %s", a.Bits, a.Machine, want)
+	}
+	return ""
+}
+
+// binarySymbols returns the identifiers a plugin binary carries: its
+// dynamic symbols plus the identifier-like strings of its data sections,
+// which is where the native tables handed to amx_Register keep their names
+func binarySymbols(path string) (map[string]bool, error) {
+	symbols := make(map[string]bool)
+	var sections [][]byte
+
+	if f, err := elf.Open(path); err == nil {
+		defer f.Close()
+		if syms, err := f.DynamicSymbols(); err == nil {
+			for _, s := range syms {
+				symbols[s.Name] = true
+			}
+		}
+		for _, s := range f.Sections {
+			if s.Type == elf.SHT_PROGBITS && s.Flags&elf.SHF_EXECINSTR == 0 {
+				if data, err := s.Data(); err == nil {
+					sections = append(sections, data)
+				}
+			}
+		}
+	} else if f, err := pe.Open(path); err == nil {
+		defer f.Close()
+		for _, s := range f.Sections {
+			if s.Characteristics&pe.IMAGE_SCN_CNT_CODE == 0 {
+				if data, err := s.Data(); err == nil {
+					sections = append(sections, data)
+				}
+			}
+		}
+	} else {
+		return nil, fmt.Errorf("not an ELF or PE binary")
+	}
+
+	for _, data := range sections {
+		for _, s := range bytes.Split(data, []byte{0}) {
+			if isIdentifier(s) {
+				symbols[string(s)] = true
+			}
+		}
+	}
+	return symbols, nil
+}
+
+// isIdentifier reports whether s could name a Pawn native
+func isIdentifier(s []byte) bool {
+	if len(s) < 3 || len(s) > 63 {
+		return false
+	}
+	for i, c := range s {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c == '_', c == '@':
+		case c >= '0' && c <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
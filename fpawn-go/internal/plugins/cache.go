@@ -0,0 +1,157 @@
+package plugins
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/FerzDevZ/fpawn/internal/core"
+)
+
+// cacheDir is fpawn's cache directory, or the system temp directory when
+// no configuration is loaded
+func cacheDir() string {
+	if core.AppConfig != nil && core.AppConfig.CacheDir != "" {
+		return core.AppConfig.CacheDir
+	}
+	return os.TempDir()
+}
+
+// blobDir is the content-addressed download cache. Blobs are named by
+// their SHA-256, so every project on the machine shares them.
+func blobDir() string {
+	return filepath.Join(cacheDir(), "plugins", "blobs")
+}
+
+// blobPath names the blob for sum, which must be a SHA-256 in lowercase
+// hex. Digests come from fpawn.lock and packs, so anything else is refused
+// rather than joined onto the cache directory.
+func blobPath(sum string) (string, error) {
+	if !validDigest(sum) {
+		return "", fmt.Errorf("invalid SHA-256 %q", sum)
+	}
+	return filepath.Join(blobDir(), sum), nil
+}
+
+// sha256Hex matches a SHA-256 digest as fpawn writes it
+var sha256Hex = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+func validDigest(sum string) bool {
+	return sha256Hex.MatchString(sum)
+}
+
+// urlRefPath records which blob a download URL served. Release assets do
+// not change once published, so a URL seen before needs no new download.
+func urlRefPath(url string) string {
+	return filepath.Join(cacheDir(), "plugins", "urls", fmt.Sprintf("%x", sha1.Sum([]byte(url))))
+}
+
+// cachedAsset returns the cached blob for url, or for want when it is set,
+// together with its SHA-256. The blob is hashed again on every read; one
+// that no longer matches its name is corrupt or tampered with, so it is
+// evicted and reported as not cached.
+func cachedAsset(url, want string) (string, string, bool) {
+	sum := want
+	if sum == "" {
+		ref, err := os.ReadFile(urlRefPath(url))
+		if err != nil {
+			return "", "", false
+		}
+		sum = strings.TrimSpace(string(ref))
+	}
+	path, err := blobPath(sum)
+	if err != nil || !fileExists(path) {
+		return "", "", false
+	}
+	got, err := hashFile(path)
+	if err != nil {
+		return "", "", false
+	}
+	if got != sum {
+		os.Remove(path)
+		return "", "", false
+	}
+	return path, sum, true
+}
+
+// fetchAsset returns the path and SHA-256 of the cached copy of url,
+// downloading it into the cache first unless it is already there. want is
+// the digest the caller expects, if it knows one. An offline client never
+// downloads.
+func (c *Client) fetchAsset(url, want string) (string, string, error) {
+	if path, sum, ok := cachedAsset(url, want); ok {
+		return path, sum, nil
+	}
+	if c.Offline {
+		return "", "", fmt.Errorf("%s is not in the plugin cache and fpawn is offline", url)
+	}
+
+	if err := os.MkdirAll(blobDir(), 0755); err != nil {
+		return "", "", err
+	}
+	tmp, err := os.CreateTemp(blobDir(), ".part-*")
+	if err != nil {
+		return "", "", err
+	}
+	tmp.Close()
+	defer os.Remove(tmp.Name())
+
+	sum, err := c.Download(url, tmp.Name())
+	if err != nil {
+		return "", "", err
+	}
+	path, err := blobPath(sum)
+	if err != nil {
+		return "", "", err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", "", err
+	}
+	rememberURL(url, sum)
+	return path, sum, nil
+}
+
+// rememberURL points url at the blob sum
+func rememberURL(url, sum string) {
+	if !validDigest(sum) {
+		return
+	}
+	ref := urlRefPath(url)
+	if err := os.MkdirAll(filepath.Dir(ref), 0755); err == nil {
+		os.WriteFile(ref, []byte(sum+"\n"), 0644)
+	}
+}
+
+// storeBlob copies r into the cache, checking it hashes to sum
+func storeBlob(r io.Reader, sum string) error {
+	path, err := blobPath(sum)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(blobDir(), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(blobDir(), ".part-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	h := sha256.New()
+	_, err = io.Copy(io.MultiWriter(tmp, h), r)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	if got := fmt.Sprintf("%x", h.Sum(nil)); got != sum {
+		return fmt.Errorf("blob %s has SHA-256 %s", sum, got)
+	}
+	return os.Rename(tmp.Name(), path)
+}
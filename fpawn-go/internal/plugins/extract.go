@@ -0,0 +1,152 @@
+package plugins
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// extractAsset unpacks a release archive into the project, placing each
+// file where layoutPath says, and returns the paths it wrote
+func extractAsset(archive, name string) ([]string, error) {
+	lower := strings.ToLower(name)
+	place := newPlacer()
+
+	switch {
+	case strings.HasSuffix(lower, ".zip"):
+		err := extractZip(archive, place)
+		return place.files, err
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		f, err := os.Open(archive)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		err = extractTar(gz, place)
+		return place.files, err
+	case strings.HasSuffix(lower, ".tar.xz"), strings.HasSuffix(lower, ".txz"):
+		// The standard library has no xz reader; every platform the server
+		// runs on ships the xz tool
+		xz, err := exec.LookPath("xz")
+		if err != nil {
+			return nil, fmt.Errorf("%s is xz-compressed; install xz to extract it", name)
+		}
+		cmd := exec.Command(xz, "-dc", archive)
+		out, err := cmd.StdoutPipe()
+		if err != nil {
+			return nil, err
+		}
+		if err := cmd.Start(); err != nil {
+			return nil, err
+		}
+		err = extractTar(out, place)
+		io.Copy(io.Discard, out)
+		if werr := cmd.Wait(); err == nil && werr != nil {
+			err = fmt.Errorf("xz: %v", werr)
+		}
+		return place.files, err
+	}
+	return nil, fmt.Errorf("%s: unsupported archive type", name)
+}
+
+// placer writes archive entries into the project layout
+type placer struct {
+	includeDir string
+	files      []string
+}
+
+func newPlacer() *placer {
+	return &placer{includeDir: projectIncludeDir()}
+}
+
+// write stores one archive entry, skipping files that have no place in
+// the project and rejecting entries that try to leave it (zip slip)
+func (p *placer) write(name string, r io.Reader, mode os.FileMode) error {
+	if !filepath.IsLocal(filepath.FromSlash(strings.ReplaceAll(name, "\\", "/"))) {
+		return fmt.Errorf("illegal file path in archive: %s", name)
+	}
+	dest := layoutPath(name, runtime.GOOS, p.includeDir)
+	if dest == "" {
+		return nil
+	}
+
+	target := filepath.FromSlash(dest)
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	if mode&0777 == 0 {
+		mode = 0644
+	}
+	if ext := filepath.Ext(target); ext == ".so" || ext == ".dll" {
+		mode |= 0755
+	}
+	out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode&0777)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, r); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	p.files = append(p.files, dest)
+	return nil
+}
+
+func extractZip(archive string, place *placer) error {
+	r, err := zip.OpenReader(archive)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	for _, f := range r.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return err
+		}
+		err = place.write(f.Name, rc, f.Mode())
+		rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func extractTar(r io.Reader, place *placer) error {
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		// Links and devices have no place in a plugin install
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		if err := place.write(hdr.Name, tr, os.FileMode(hdr.Mode)); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package plugins
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// GitHubRelease represents a GitHub release
+type GitHubRelease struct {
+	TagName string  `json:"tag_name"`
+	Assets  []Asset `json:"assets"`
+}
+
+// Asset represents a release asset
+type Asset struct {
+	Name        string `json:"name"`
+	DownloadURL string `json:"browser_download_url"`
+	Size        int64  `json:"size"`
+}
+
+// DefaultAPI is the GitHub API root used when no mirror is given
+const DefaultAPI = "https://api.github.com"
+
+// DefaultRaw serves repository files for plugins without release binaries
+const DefaultRaw = "https://raw.githubusercontent.com"
+
+// Client talks to a GitHub-compatible API. Point BaseURL and RawURL at a
+// mirror or a local stand-in to install without reaching github.com.
+type Client struct {
+	BaseURL string
+	RawURL  string
+	HTTP    *http.Client
+	// Offline answers from the download cache and the releases of an
+	// imported pack only, and fails instead of reaching the network
+	Offline bool
+
+	// releases are the releases an imported pack carries, by repository
+	releases map[string][]GitHubRelease
+}
+
+// NewClient returns a client for the API behind baseURL, or api.github.com
+// when baseURL is empty. A custom API also serves the raw files, under
+// /raw/<owner>/<repo>/<branch>/<path>.
+func NewClient(baseURL string) *Client {
+	c := &Client{BaseURL: DefaultAPI, RawURL: DefaultRaw, HTTP: http.DefaultClient}
+	if baseURL != "" {
+		c.BaseURL = strings.TrimSuffix(baseURL, "/")
+		c.RawURL = c.BaseURL + "/raw"
+	}
+	return c
+}
+
+// LatestRelease returns the newest published release of repo
+func (c *Client) LatestRelease(repo string) (*GitHubRelease, error) {
+	if c.Offline {
+		return c.packRelease(repo, "")
+	}
+	return c.fetchRelease(fmt.Sprintf("%s/repos/%s/releases/latest", c.BaseURL, repo))
+}
+
+// Release returns the release of repo tagged tag
+func (c *Client) Release(repo, tag string) (*GitHubRelease, error) {
+	if c.Offline {
+		return c.packRelease(repo, tag)
+	}
+	return c.fetchRelease(fmt.Sprintf("%s/repos/%s/releases/tags/%s", c.BaseURL, repo, tag))
+}
+
+// Releases returns the published releases of repo, newest first as the API
+// lists them
+func (c *Client) Releases(repo string) ([]GitHubRelease, error) {
+	if c.Offline {
+		if releases := c.releases[repo]; len(releases) > 0 {
+			return releases, nil
+		}
+		return nil, fmt.Errorf("%s is not in the imported pack and fpawn is offline", repo)
+	}
+	resp, err := c.HTTP.Get(fmt.Sprintf("%s/repos/%s/releases?per_page=100", c.BaseURL, repo))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GitHub API returned status %d", resp.StatusCode)
+	}
+
+	var releases []GitHubRelease
+	if err := json.NewDecoder(resp.Body).Decode(&releases); err != nil {
+		return nil, err
+	}
+	return releases, nil
+}
+
+// packRelease serves a release from the imported pack: the one tagged tag,
+// or the newest when tag is empty
+func (c *Client) packRelease(repo, tag string) (*GitHubRelease, error) {
+	var best *GitHubRelease
+	var bestVersion version
+	for i, r := range c.releases[repo] {
+		if tag != "" {
+			if r.TagName == tag {
+				return &c.releases[repo][i], nil
+			}
+			continue
+		}
+		v, _ := parseVersion(r.TagName)
+		if best == nil || (v != nil && (bestVersion == nil || v.compare(bestVersion) > 0)) {
+			best, bestVersion = &c.releases[repo][i], v
+		}
+	}
+	if best == nil {
+		what := repo
+		if tag != "" {
+			what += " " + tag
+		}
+		return nil, fmt.Errorf("%s is not in the imported pack and fpawn is offline", what)
+	}
+	return best, nil
+}
+
+func (c *Client) fetchRelease(url string) (*GitHubRelease, error) {
+	resp, err := c.HTTP.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GitHub API returned status %d", resp.StatusCode)
+	}
+
+	var release GitHubRelease
+	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+		return nil, err
+	}
+	return &release, nil
+}
+
+// Download saves url to dest and returns the SHA-256 of what was written
+func (c *Client) Download(url, dest string) (string, error) {
+	resp, err := c.HTTP.Get(url)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("download %s: HTTP %d", url, resp.StatusCode)
+	}
+
+	out, err := os.Create(dest)
+	if err != nil {
+		return "", err
+	}
+	defer out.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(out, h), resp.Body); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
+
+// RawFile returns the URL of path on branch of repo
+func (c *Client) RawFile(repo, branch, path string) string {
+	return fmt.Sprintf("%s/%s/%s/%s", c.RawURL, repo, branch, path)
+}
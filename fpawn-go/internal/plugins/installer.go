@@ -1,71 +1,147 @@
 package plugins
 
 import (
-	"archive/zip"
-	"encoding/json"
 	"fmt"
 	"io"
-	"net/http"
+	"net/url"
 	"os"
+	"path"
 	"path/filepath"
 	"runtime"
 	"strings"
 
 	"github.com/FerzDevZ/fpawn/internal/core"
+	"github.com/FerzDevZ/fpawn/internal/manifest"
+	"github.com/FerzDevZ/fpawn/internal/servercfg"
 )
 
-// GitHubRelease represents a GitHub release
-type GitHubRelease struct {
-	TagName string  `json:"tag_name"`
-	Assets  []Asset `json:"assets"`
+// InstallOptions controls InstallPlugins
+type InstallOptions struct {
+	// Force installs plugins built for the other ecosystem
+	Force bool
+	// DryRun prints the plan without installing anything
+	DryRun bool
 }
 
-// Asset represents a release asset
-type Asset struct {
-	Name        string `json:"name"`
-	DownloadURL string `json:"browser_download_url"`
-	Size        int64  `json:"size"`
-}
-
-// InstallPlugin downloads and installs a plugin
-func InstallPlugin(name string) error {
-	plugin := GetPluginByName(name)
-	if plugin == nil {
-		return fmt.Errorf("plugin '%s' not found in database", name)
+// InstallPlugins resolves specs ("name", "name@constraint" or a location
+// ParseSource accepts) and their dependencies, prints the plan, then
+// installs it dependencies first and records each plugin in fpawn.lock. It
+// stops at the first failure, so nothing is installed without what it
+// depends on.
+func InstallPlugins(specs []string, c *Client, opts InstallOptions) (*Plan, error) {
+	lock, err := LoadLock()
+	if err != nil {
+		return nil, err
 	}
 
-	fmt.Printf("\n %s Installing: %s\n", core.LBlue("📦"), core.Bold(name))
+	fmt.Printf("\n %s Installing: %s\n", core.LBlue("📦"), core.Bold(strings.Join(specs, ", ")))
 	fmt.Println(" ──────────────────────────────────────────────────")
 
-	// Create directories
-	os.MkdirAll("plugins", 0755)
-	os.MkdirAll("include", 0755)
-
 	// Pillar VIII: THE ALCHEMIST (Smart Dependency Resolution)
-	if len(plugin.Deps) > 0 {
-		fmt.Printf(" %s The Alchemist: Resolving %d dependencies...\n", core.Magenta("🧪"), len(plugin.Deps))
-		for _, depName := range plugin.Deps {
-			// Check if already installed
-			if !isPluginInstalled(depName) {
-				fmt.Printf("   ➜ Auto-installing missing dependency: %s\n", core.Bold(depName))
-				if err := InstallPlugin(depName); err != nil {
-					fmt.Printf("   %s Failed to resolve dependency '%s': %v\n", core.Yellow("[Warn]"), depName, err)
+	fmt.Printf(" %s The Alchemist: Resolving dependencies...\n", core.Magenta("🧪"))
+	plan, err := PlanInstall(specs, c, lock, opts.Force)
+	if err != nil {
+		return nil, err
+	}
+	PrintPlan(plan)
+	if opts.DryRun {
+		return plan, nil
+	}
+
+	m := manifest.Current()
+	for _, step := range plan.Steps {
+		if step.Action != "install" {
+			continue
+		}
+		fmt.Printf("\n %s %s %s\n", core.LBlue("📦"), core.Bold(step.Name), step.Tag)
+		var entry *LockedPlugin
+		if step.source != nil {
+			entry, err = installSource(step.source, c)
+		} else {
+			entry, err = installRelease(GetPluginByName(step.Name), step.Tag, c)
+		}
+		if err != nil {
+			return plan, fmt.Errorf("%s: %v (nothing that depends on it was installed)", step.Name, err)
+		}
+		lock.Put(entry)
+		if err := lock.Save(); err != nil {
+			return plan, err
+		}
+
+		// Update server.cfg
+		if entry.hasBinary() {
+			for _, load := range loadNames(entry, step.source != nil) {
+				updateServerCfg(load)
+				if m != nil {
+					if rt := m.MainRuntime(); rt != nil && !containsString(rt.Plugins, load) {
+						fmt.Printf(" %s %s defines a runtime; add \"%s\" to runtime.plugins to keep it loaded\n", core.Cyan("[Tip]"), m.Path, load)
+					}
 				}
 			}
 		}
+		fmt.Printf(" %s %s installed successfully!\n", core.Green("✓"), step.Name)
 	}
+	return plan, nil
+}
 
-	// Get latest release from GitHub
+// PrintPlan renders an install plan for the terminal
+func PrintPlan(plan *Plan) {
+	fmt.Printf(" %s Project targets %s\n", core.Cyan("[Plan]"), plan.Ecosystem)
+	for _, w := range plan.Warnings {
+		fmt.Printf(" %s %s\n", core.Yellow("[Warn]"), w)
+	}
+	for i, step := range plan.Steps {
+		action := core.Green("install")
+		if step.Action == "keep" {
+			action = core.Cyan("keep   ")
+		}
+		tag := step.Tag
+		if tag == "" {
+			tag = "-"
+		}
+		why := ""
+		if len(step.RequiredBy) > 0 {
+			why = "required by " + strings.Join(step.RequiredBy, ", ")
+		}
+		if step.Constraint != "*" {
+			why = strings.TrimSpace(step.Constraint + "  " + why)
+		}
+		if step.Source != "" {
+			why = strings.TrimSpace("from " + step.Source + "  " + why)
+		}
+		fmt.Printf("   %d. %s %-20s %-12s %s\n", i+1, action, step.Name, tag, why)
+	}
+	fmt.Println(" ──────────────────────────────────────────────────")
+}
+
+// installRelease installs the release of plugin tagged tag, or the latest
+// one when tag is empty, and returns its lock entry
+func installRelease(plugin *Plugin, tag string, c *Client) (*LockedPlugin, error) {
 	repoPath := extractRepoPath(plugin.URL)
 	if repoPath == "" {
-		return fmt.Errorf("invalid GitHub URL")
+		if src, ok, err := ParseSource(plugin.URL); err == nil && ok && src.Kind == SourceGit {
+			// Registries may list plugins hosted outside GitHub
+			src.Name, src.Ref = plugin.Name, tag
+			return installSource(src, c)
+		}
+		return nil, fmt.Errorf("%s is not a GitHub or git repository URL", plugin.URL)
 	}
 
-	fmt.Printf(" %s Fetching latest release...\n", core.Cyan("[GitHub]"))
-
-	release, err := getLatestRelease(repoPath)
+	var release *GitHubRelease
+	var err error
+	source := "[GitHub]"
+	if c.Offline {
+		source = "[Offline]"
+	}
+	if tag == "" {
+		fmt.Printf(" %s Fetching latest release...\n", core.Cyan(source))
+		release, err = c.LatestRelease(repoPath)
+	} else {
+		fmt.Printf(" %s Fetching release %s...\n", core.Cyan(source), tag)
+		release, err = c.Release(repoPath, tag)
+	}
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	fmt.Printf(" %s Version: %s\n", core.Green("[Found]"), release.TagName)
@@ -74,163 +150,112 @@ func InstallPlugin(name string) error {
 	asset := findAsset(release.Assets)
 	if asset == nil {
 		fmt.Printf(" %s No binary found, trying to download include files...\n", core.Yellow("[Warn]"))
-		return downloadIncludes(plugin)
+		return downloadIncludes(plugin, c)
 	}
 
-	fmt.Printf(" %s Downloading: %s (%d KB)\n", core.Blue("[Download]"), asset.Name, asset.Size/1024)
-
-	// Download asset
-	tempFile := filepath.Join(os.TempDir(), asset.Name)
-	if err := downloadFile(asset.DownloadURL, tempFile); err != nil {
-		return err
+	if _, _, ok := cachedAsset(asset.DownloadURL, ""); ok {
+		fmt.Printf(" %s Using cached: %s\n", core.Blue("[Cache]"), asset.Name)
+	} else {
+		fmt.Printf(" %s Downloading: %s (%d KB)\n", core.Blue("[Download]"), asset.Name, asset.Size/1024)
 	}
-	defer os.Remove(tempFile)
 
-	// Extract if it's an archive
-	if strings.HasSuffix(asset.Name, ".zip") {
-		if err := extractZip(tempFile, "."); err != nil {
-			return err
-		}
-	} else if strings.HasSuffix(asset.Name, ".so") || strings.HasSuffix(asset.Name, ".dll") {
-		// Direct plugin file
-		destPath := filepath.Join("plugins", asset.Name)
-		if err := copyFile(tempFile, destPath); err != nil {
-			return err
-		}
-		os.Chmod(destPath, 0755)
+	entry := &LockedPlugin{
+		Name:  plugin.Name,
+		Repo:  repoPath,
+		Tag:   release.TagName,
+		Asset: asset.Name,
+		URL:   asset.DownloadURL,
 	}
-
-	// Update server.cfg
-	updateServerCfg(name)
-
-	fmt.Printf(" %s %s installed successfully!\n", core.Green("✓"), name)
-	return nil
-}
-
-func extractRepoPath(url string) string {
-	url = strings.TrimSuffix(url, ".git")
-	url = strings.TrimPrefix(url, "https://github.com/")
-	url = strings.TrimPrefix(url, "http://github.com/")
-	if strings.Contains(url, "/") {
-		return url
+	if err := fetchLocked(entry, c); err != nil {
+		return nil, err
 	}
-	return ""
+	return entry, nil
 }
 
-func getLatestRelease(repoPath string) (*GitHubRelease, error) {
-	url := fmt.Sprintf("https://api.github.com/repos/%s/releases/latest", repoPath)
-
-	resp, err := http.Get(url)
+// fetchLocked fetches entry.URL through the download cache (or from the
+// local path or git repository it names) and places its files. An entry
+// that already has a SHA-256 must match it; otherwise the digest and the
+// placed files are filled in. The digest of every placed file is recorded
+// for --verify.
+func fetchLocked(entry *LockedPlugin, c *Client) error {
+	src, err := c.fetchSource(entry)
 	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("GitHub API returned status %d", resp.StatusCode)
+		return err
 	}
-
-	var release GitHubRelease
-	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
-		return nil, err
+	defer src.done()
+	if entry.SHA256 != "" && !strings.EqualFold(entry.SHA256, src.sum) {
+		return fmt.Errorf("checksum mismatch for %s: %s expects %s, got %s", entry.Asset, LockFile, entry.SHA256, src.sum)
 	}
+	entry.SHA256 = src.sum
 
-	return &release, nil
-}
-
-func findAsset(assets []Asset) *Asset {
-	osName := runtime.GOOS
-	keywords := []string{}
-
-	switch osName {
-	case "linux":
-		keywords = []string{".so", "linux", "ubuntu"}
-	case "windows":
-		keywords = []string{".dll", "windows", "win32", "win64"}
-	case "darwin":
-		keywords = []string{".dylib", "macos", "darwin"}
+	var files []string
+	if src.dir {
+		files, err = placeTree(src.path)
+	} else {
+		files, err = placeAsset(src.path, entry.Asset)
 	}
-
-	for _, asset := range assets {
-		name := strings.ToLower(asset.Name)
-		for _, kw := range keywords {
-			if strings.Contains(name, kw) {
-				return &asset
-			}
-		}
+	if err != nil {
+		return err
 	}
-
-	// Try to find any archive
-	for _, asset := range assets {
-		if strings.HasSuffix(asset.Name, ".zip") || strings.HasSuffix(asset.Name, ".tar.gz") {
-			return &asset
+	entry.Files = files
+	entry.Hashes = make(map[string]string, len(files))
+	for _, f := range files {
+		if h, err := hashFile(f); err == nil {
+			entry.Hashes[f] = h
 		}
 	}
-
 	return nil
 }
 
-func downloadFile(url, dest string) error {
-	resp, err := http.Get(url)
-	if err != nil {
-		return err
+// placeAsset unpacks or copies a downloaded asset into the project and
+// returns the paths it wrote
+func placeAsset(path, name string) ([]string, error) {
+	lower := strings.ToLower(name)
+	switch ext := filepath.Ext(lower); {
+	case ext == ".so" || ext == ".dll" || ext == ".inc":
+		// A bare file goes wherever the same file inside an archive would
+		destPath := filepath.FromSlash(layoutPath(filepath.Base(name), runtime.GOOS, projectIncludeDir()))
+		if destPath == "" {
+			return nil, fmt.Errorf("%s is not built for %s", name, runtime.GOOS)
+		}
+		os.MkdirAll(filepath.Dir(destPath), 0755)
+		if sameFile(path, destPath) {
+			return []string{filepath.ToSlash(destPath)}, nil
+		}
+		if err := copyFile(path, destPath); err != nil {
+			return nil, err
+		}
+		if ext != ".inc" {
+			os.Chmod(destPath, 0755)
+		}
+		return []string{filepath.ToSlash(destPath)}, nil
 	}
-	defer resp.Body.Close()
-
-	out, err := os.Create(dest)
+	files, err := extractAsset(path, name)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	defer out.Close()
-
-	_, err = io.Copy(out, resp.Body)
-	return err
+	if len(files) == 0 {
+		return nil, fmt.Errorf("%s contains no plugin or include files for %s", name, runtime.GOOS)
+	}
+	return files, nil
 }
 
-func extractZip(zipPath, destDir string) error {
-	r, err := zip.OpenReader(zipPath)
-	if err != nil {
-		return err
+// extractRepoPath returns owner/repo for a github.com URL, ignoring any
+// path below the repository ("/tree/master", "/releases"), and "" for
+// every other host
+func extractRepoPath(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") {
+		return ""
 	}
-	defer r.Close()
-
-	for _, f := range r.File {
-		fpath := filepath.Join(destDir, f.Name)
-
-		// Check for zip slip
-		if !strings.HasPrefix(fpath, filepath.Clean(destDir)+string(os.PathSeparator)) {
-			continue
-		}
-
-		if f.FileInfo().IsDir() {
-			os.MkdirAll(fpath, os.ModePerm)
-			continue
-		}
-
-		if err := os.MkdirAll(filepath.Dir(fpath), os.ModePerm); err != nil {
-			return err
-		}
-
-		outFile, err := os.OpenFile(fpath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, f.Mode())
-		if err != nil {
-			return err
-		}
-
-		rc, err := f.Open()
-		if err != nil {
-			outFile.Close()
-			return err
-		}
-
-		_, err = io.Copy(outFile, rc)
-		outFile.Close()
-		rc.Close()
-
-		if err != nil {
-			return err
-		}
+	if host := strings.ToLower(u.Host); host != "github.com" && host != "www.github.com" {
+		return ""
 	}
-	return nil
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return ""
+	}
+	return parts[0] + "/" + strings.TrimSuffix(parts[1], ".git")
 }
 
 func copyFile(src, dst string) error {
@@ -250,60 +275,65 @@ func copyFile(src, dst string) error {
 	return err
 }
 
-func downloadIncludes(plugin *Plugin) error {
-	// Try to download raw include file from main branch
+// downloadIncludes fetches the include file of a plugin that publishes no
+// binaries from its default branch
+func downloadIncludes(plugin *Plugin, c *Client) (*LockedPlugin, error) {
 	repoPath := extractRepoPath(plugin.URL)
-	urls := []string{
-		fmt.Sprintf("https://raw.githubusercontent.com/%s/master/%s.inc", repoPath, plugin.Name),
-		fmt.Sprintf("https://raw.githubusercontent.com/%s/main/%s.inc", repoPath, plugin.Name),
-		fmt.Sprintf("https://raw.githubusercontent.com/%s/master/include/%s.inc", repoPath, plugin.Name),
+	candidates := [][2]string{
+		{"master", plugin.Name + ".inc"},
+		{"main", plugin.Name + ".inc"},
+		{"master", "include/" + plugin.Name + ".inc"},
 	}
 
-	for _, url := range urls {
-		resp, err := http.Get(url)
-		if err != nil {
+	for _, cand := range candidates {
+		entry := &LockedPlugin{
+			Name:  plugin.Name,
+			Repo:  repoPath,
+			Asset: cand[1],
+			URL:   c.RawFile(repoPath, cand[0], cand[1]),
+		}
+		if err := fetchLocked(entry, c); err != nil {
 			continue
 		}
-		defer resp.Body.Close()
+		fmt.Printf(" %s Include file installed: %s\n", core.Green("✓"), entry.Files[0])
+		return entry, nil
+	}
 
-		if resp.StatusCode == 200 {
-			incPath := filepath.Join("include", plugin.Name+".inc")
-			out, err := os.Create(incPath)
-			if err != nil {
-				return err
-			}
-			defer out.Close()
-			io.Copy(out, resp.Body)
-			fmt.Printf(" %s Include file installed: %s\n", core.Green("✓"), incPath)
-			return nil
+	return nil, fmt.Errorf("could not find include files")
+}
+
+// loadNames returns the names server.cfg loads an installed plugin by:
+// the index name, or for a plugin given by location the name of each
+// binary it placed in plugins/ (open.mp loads components/ by itself)
+func loadNames(entry *LockedPlugin, located bool) []string {
+	if !located {
+		return []string{entry.Name}
+	}
+	var names []string
+	for _, f := range entry.Files {
+		if isBinary(f) && path.Dir(f) == "plugins" {
+			names = append(names, strings.TrimSuffix(path.Base(f), path.Ext(f)))
 		}
 	}
-
-	return fmt.Errorf("could not find include files")
+	return names
 }
 
 func updateServerCfg(pluginName string) {
-	cfgPath := "server.cfg"
-	data, err := os.ReadFile(cfgPath)
+	cfg, err := servercfg.Open()
 	if err != nil {
 		return
 	}
-
-	content := string(data)
-	if strings.Contains(content, pluginName) {
-		return // Already added
+	added, err := cfg.AddPlugin(pluginName)
+	if err == nil && added {
+		err = cfg.Save()
 	}
-
-	lines := strings.Split(content, "\n")
-	for i, line := range lines {
-		if strings.HasPrefix(line, "plugins") {
-			lines[i] = line + " " + pluginName
-			break
-		}
+	if err != nil {
+		fmt.Printf(" %s Could not update %s: %v\n", core.Yellow("[Warn]"), cfg.Path, err)
+		return
+	}
+	if added {
+		fmt.Printf(" %s Added to %s\n", core.Blue("[Config]"), cfg.Path)
 	}
-
-	os.WriteFile(cfgPath, []byte(strings.Join(lines, "\n")), 0644)
-	fmt.Printf(" %s Added to server.cfg\n", core.Blue("[Config]"))
 }
 
 // ListPlugins displays all available plugins
@@ -320,11 +350,15 @@ func ListPlugins() {
 			if p.Compat == "Legacy" {
 				compat = core.Yellow(p.Compat)
 			}
-			fmt.Printf("   • %-20s %s - %s\n", p.Name, compat, p.Description)
+			source := ""
+			if p.Registry != "official" {
+				source = core.Cyan(" [" + p.Registry + "]")
+			}
+			fmt.Printf("   • %-20s %s - %s%s\n", p.Name, compat, p.Description, source)
 		}
 	}
 
-	fmt.Printf("\n %s Total: %d plugins\n", core.Cyan("[Info]"), len(PluginDatabase))
+	fmt.Printf("\n %s Total: %d plugins from %d registries\n", core.Cyan("[Info]"), len(Catalogue()), len(RegistryReport()))
 }
 
 // SearchPlugins searches for plugins by name or description
@@ -332,7 +366,7 @@ func SearchPlugins(query string) []Plugin {
 	query = strings.ToLower(query)
 	var results []Plugin
 
-	for _, p := range PluginDatabase {
+	for _, p := range Catalogue() {
 		if strings.Contains(strings.ToLower(p.Name), query) ||
 			strings.Contains(strings.ToLower(p.Description), query) {
 			results = append(results, p)
@@ -342,16 +376,30 @@ func SearchPlugins(query string) []Plugin {
 	return results
 }
 
-func isPluginInstalled(name string) bool {
-paths := []string{
-filepath.Join("plugins", name+".so"),
-filepath.Join("plugins", name+".dll"),
-filepath.Join("include", name+".inc"),
-}
-for _, p := range paths {
-if _, err := os.Stat(p); err == nil {
-return true
-}
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
 }
-return false
+
+// isPluginInstalled trusts fpawn.lock for plugins it records and falls back
+// to looking for the usual file names for plugins placed by hand
+func isPluginInstalled(lock *Lock, name string) bool {
+	if entry := lock.Find(name); entry != nil {
+		return entry.Present()
+	}
+	paths := []string{
+		filepath.Join("plugins", name+".so"),
+		filepath.Join("plugins", name+".dll"),
+		filepath.Join(projectIncludeDir(), name+".inc"),
+	}
+	for _, p := range paths {
+		if fileExists(p) {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,137 @@
+package plugins
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// LockFile records the exact plugin set of a project, next to pawn.json
+const LockFile = "fpawn.lock"
+
+// lockVersion is bumped when the lock file layout changes
+const lockVersion = 1
+
+// LockedPlugin is one installed plugin as it was resolved
+type LockedPlugin struct {
+	Name string `json:"name"`
+	Repo string `json:"repo"`
+	// Tag is the release installed; empty for include files fetched from
+	// the default branch of plugins without releases
+	Tag    string `json:"tag,omitempty"`
+	Asset  string `json:"asset"`
+	URL    string `json:"url"`
+	SHA256 string `json:"sha256"`
+	// Files lists every path the install placed, relative to the project
+	Files []string `json:"files"`
+	// Hashes maps each placed file to its SHA-256, checked by --verify
+	Hashes map[string]string `json:"hashes,omitempty"`
+}
+
+// Lock is the contents of fpawn.lock
+type Lock struct {
+	Version int             `json:"version"`
+	Plugins []*LockedPlugin `json:"plugins"`
+}
+
+// LoadLock reads fpawn.lock from the working directory. A missing file is
+// an empty lock.
+func LoadLock() (*Lock, error) {
+	data, err := os.ReadFile(LockFile)
+	if os.IsNotExist(err) {
+		return &Lock{Version: lockVersion}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var l Lock
+	if err := json.Unmarshal(data, &l); err != nil {
+		return nil, fmt.Errorf("%s: %v", LockFile, err)
+	}
+	if l.Version > lockVersion {
+		return nil, fmt.Errorf("%s was written by a newer fpawn (version %d)", LockFile, l.Version)
+	}
+	for _, p := range l.Plugins {
+		if err := p.validate(); err != nil {
+			return nil, fmt.Errorf("%s: %v", LockFile, err)
+		}
+	}
+	return &l, nil
+}
+
+// validate rejects an entry whose digest or file list could reach outside
+// the download cache or the project. Lock files and packs come from other
+// people's projects, so neither is trusted.
+func (p *LockedPlugin) validate() error {
+	if !validDigest(p.SHA256) {
+		return fmt.Errorf("%s: invalid SHA-256 %q", p.Name, p.SHA256)
+	}
+	for _, f := range p.Files {
+		if !filepath.IsLocal(filepath.FromSlash(f)) {
+			return fmt.Errorf("%s: file %q is outside the project", p.Name, f)
+		}
+	}
+	return nil
+}
+
+// Save writes the lock with plugins sorted by name, so it diffs cleanly
+func (l *Lock) Save() error {
+	l.Version = lockVersion
+	sort.Slice(l.Plugins, func(i, j int) bool { return l.Plugins[i].Name < l.Plugins[j].Name })
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(LockFile, append(data, '\n'), 0644)
+}
+
+// Find returns the entry for name, or nil
+func (l *Lock) Find(name string) *LockedPlugin {
+	for _, p := range l.Plugins {
+		if p.Name == name {
+			return p
+		}
+	}
+	return nil
+}
+
+// Put adds entry, replacing any earlier entry for the same plugin
+func (l *Lock) Put(entry *LockedPlugin) {
+	l.Remove(entry.Name)
+	l.Plugins = append(l.Plugins, entry)
+}
+
+// Remove drops the entry for name and reports whether there was one
+func (l *Lock) Remove(name string) bool {
+	for i, p := range l.Plugins {
+		if p.Name == name {
+			l.Plugins = append(l.Plugins[:i], l.Plugins[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Present reports whether every file the entry placed is still there
+func (p *LockedPlugin) Present() bool {
+	for _, f := range p.Files {
+		if !fileExists(f) {
+			return false
+		}
+	}
+	return len(p.Files) > 0
+}
+
+// hasBinary reports whether the entry placed a server plugin, as opposed
+// to only include files
+func (p *LockedPlugin) hasBinary() bool {
+	for _, f := range p.Files {
+		if isBinary(f) {
+			return true
+		}
+	}
+	return false
+}
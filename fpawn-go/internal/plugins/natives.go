@@ -0,0 +1,464 @@
+package plugins
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/FerzDevZ/fpawn/internal/compiler"
+	"github.com/FerzDevZ/fpawn/internal/servercfg"
+)
+
+// serverIncludes declare the natives the server itself provides
+var serverIncludes = map[string]bool{
+	"a_samp": true, "a_players": true, "a_vehicles": true, "a_objects": true,
+	"a_actor": true, "a_http": true, "a_sampdb": true, "a_npc": true,
+	"core": true, "float": true, "string": true, "file": true, "time": true,
+	"console": true, "datagram": true, "args": true, "rational": true,
+	"open.mp": true, "_open_mp": true,
+}
+
+// nativeDecl matches "native Tag:Name(" and captures Name
+var nativeDecl = regexp.MustCompile(`^\s*native\s+(?:[A-Za-z_@][\w@]*\s*:\s*)?([A-Za-z_@][\w@]*)\s*\(`)
+
+// nativeAlias matches the "= Symbol;" that registers a native under
+// another name
+var nativeAlias = regexp.MustCompile(`\)\s*=\s*([A-Za-z_@][\w@]*)\s*;`)
+
+var identifier = regexp.MustCompile(`[A-Za-z_@][\w@]*`)
+
+// PluginUsage is one plugin as seen from the natives the project calls
+type PluginUsage struct {
+	Name string `json:"name"`
+	// Entry is how the server config names the plugin, if it loads it
+	Entry    string `json:"entry,omitempty"`
+	Position int    `json:"position,omitempty"`
+	// Component is set for open.mp components, which load themselves
+	Component bool `json:"component,omitempty"`
+	// Evidence is how its natives were attributed: "lock", "binary" or "index"
+	Evidence string   `json:"evidence,omitempty"`
+	Declared int      `json:"declared"`
+	Used     []string `json:"used"`
+	// Status is "ok", "missing", "unused" or "order"
+	Status  string `json:"status"`
+	Problem string `json:"problem,omitempty"`
+}
+
+// DependencyReport compares the natives a gamemode uses with the plugins
+// its server loads
+type DependencyReport struct {
+	Entry   string        `json:"entry"`
+	Config  string        `json:"config"`
+	Files   int           `json:"files"`
+	Natives int           `json:"natives"`
+	Plugins []PluginUsage `json:"plugins"`
+	// Unresolved lists called natives no known plugin provides
+	Unresolved []string `json:"unresolved"`
+	Missing    int      `json:"missing"`
+	Unused     int      `json:"unused"`
+	Misordered int      `json:"misordered"`
+}
+
+// Failed reports whether the server would miss natives or load plugins
+// before their dependencies
+func (r *DependencyReport) Failed() bool {
+	return r.Missing+r.Misordered > 0
+}
+
+// native is one native declaration
+type native struct {
+	name string
+	// symbol is the name the plugin registers it under
+	symbol string
+	file   string
+}
+
+// AnalyzeDependencies walks the include graph of target (the entry point
+// when empty), attributes every native declared in it to the server or a
+// plugin, and checks the result against the plugins the server config
+// loads. Natives are attributed through the server's own includes, the
+// include files fpawn.lock records, the names the installed plugin
+// binaries carry, and the include names the plugin index lists, in that
+// order.
+func AnalyzeDependencies(target string) (*DependencyReport, error) {
+	if target == "" {
+		target = compiler.FindEntryPoint()
+	}
+	if target == "" {
+		return nil, fmt.Errorf("no entry point found")
+	}
+	cfg, err := servercfg.Open()
+	if err != nil {
+		return nil, err
+	}
+	graph, err := compiler.SourceGraph(target, compiler.ProfileAuto)
+	if err != nil {
+		return nil, err
+	}
+	lock, err := LoadLock()
+	if err != nil {
+		return nil, err
+	}
+
+	// Collect the declarations, keeping each file with comments and
+	// strings blanked out for the usage scan
+	var natives []native
+	sources := make(map[string]string, len(graph))
+	for _, path := range graph {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		src := blankPawn(string(data))
+		sources[path] = src
+		for _, line := range strings.Split(src, "\n") {
+			if m := nativeDecl.FindStringSubmatch(line); m != nil {
+				n := native{name: m[1], symbol: m[1], file: path}
+				if a := nativeAlias.FindStringSubmatch(line); a != nil {
+					n.symbol = a[1]
+				}
+				natives = append(natives, n)
+			}
+		}
+	}
+
+	// A native is used when a file other than the one declaring it names
+	// it; includes often wrap their own natives in stocks nobody calls
+	declared := make(map[string]bool, len(natives))
+	for _, n := range natives {
+		declared[n.name] = true
+	}
+	usedIn := make(map[string][]string)
+	for path, src := range sources {
+		seen := make(map[string]bool)
+		for _, line := range strings.Split(src, "\n") {
+			if nativeDecl.MatchString(line) {
+				continue
+			}
+			for _, id := range identifier.FindAllString(line, -1) {
+				if declared[id] && !seen[id] {
+					seen[id] = true
+					usedIn[id] = append(usedIn[id], path)
+				}
+			}
+		}
+	}
+	used := func(n native) bool {
+		for _, f := range usedIn[n.name] {
+			if f != n.file {
+				return true
+			}
+		}
+		return false
+	}
+
+	attr := newAttribution(lock)
+	report := &DependencyReport{
+		Entry:      target,
+		Config:     cfg.Path,
+		Files:      len(graph),
+		Natives:    len(natives),
+		Plugins:    []PluginUsage{},
+		Unresolved: []string{},
+	}
+	usage := make(map[string]*PluginUsage)
+	use := func(name string) *PluginUsage {
+		if usage[name] == nil {
+			usage[name] = &PluginUsage{Name: name, Used: []string{}}
+		}
+		return usage[name]
+	}
+
+	for _, n := range natives {
+		if isServerInclude(n.file) {
+			attr.server[n.symbol] = true
+		}
+	}
+	for _, n := range natives {
+		if attr.server[n.symbol] {
+			continue
+		}
+		name, evidence, component := attr.provider(n)
+		if name == "" {
+			if used(n) {
+				report.Unresolved = append(report.Unresolved, n.name)
+			}
+			continue
+		}
+		u := use(name)
+		u.Component = u.Component || component
+		if u.Evidence == "" {
+			u.Evidence = evidence
+		}
+		u.Declared++
+		if used(n) {
+			u.Used = append(u.Used, n.name)
+		}
+	}
+	sort.Strings(report.Unresolved)
+
+	// Match the config's plugin lines to the plugins above
+	position := make(map[string]int)
+	for i, entry := range cfg.Plugins() {
+		name := attr.configName(entry)
+		u := use(name)
+		u.Entry, u.Position = entry, i+1
+		position[name] = i + 1
+	}
+
+	for _, u := range usage {
+		plugin := GetPluginByName(u.Name)
+		switch {
+		case u.Position == 0 && !u.Component:
+			if len(u.Used) == 0 {
+				// Declared by an include nothing calls into
+				delete(usage, u.Name)
+				continue
+			}
+			u.Status = "missing"
+			u.Problem = fmt.Sprintf("%d natives used but %s does not load it", len(u.Used), filepath.Base(cfg.Path))
+			report.Missing++
+			continue
+		case u.Component:
+			u.Status = "ok"
+			continue
+		}
+
+		u.Status = "ok"
+		if plugin != nil {
+			for _, dep := range plugin.Deps {
+				depName, _, err := ParseDep(dep)
+				if err != nil {
+					continue
+				}
+				if p := position[depName]; p > u.Position {
+					u.Status = "order"
+					u.Problem = fmt.Sprintf("loaded before its dependency %s", depName)
+					report.Misordered++
+					break
+				}
+			}
+		}
+		if u.Status == "ok" && len(u.Used) == 0 && (plugin == nil || !plugin.Standalone) {
+			u.Status = "unused"
+			if u.Declared > 0 {
+				u.Problem = "its include is in the build but none of its natives are called"
+			} else {
+				u.Problem = "no include in the build declares its natives"
+			}
+			report.Unused++
+		}
+	}
+
+	for _, u := range usage {
+		report.Plugins = append(report.Plugins, *u)
+	}
+	sort.Slice(report.Plugins, func(i, j int) bool {
+		a, b := report.Plugins[i], report.Plugins[j]
+		if (a.Position == 0) != (b.Position == 0) {
+			return a.Position != 0
+		}
+		if a.Position != b.Position {
+			return a.Position < b.Position
+		}
+		return a.Name < b.Name
+	})
+	return report, nil
+}
+
+// attribution maps native declarations to the plugins that provide them
+type attribution struct {
+	// server holds the symbols the server's own includes declare
+	server map[string]bool
+	// includes maps include files fpawn.lock records to their plugin
+	includes map[string]string
+	// binaries are the installed plugin and component binaries
+	binaries []pluginBinary
+}
+
+type pluginBinary struct {
+	name      string
+	file      string
+	component bool
+	symbols   map[string]bool
+}
+
+func newAttribution(lock *Lock) *attribution {
+	a := &attribution{server: make(map[string]bool), includes: make(map[string]string)}
+	owners := make(map[string]string)
+	for _, entry := range lock.Plugins {
+		for _, f := range entry.Files {
+			owners[f] = entry.Name
+			if strings.HasSuffix(f, ".inc") {
+				a.includes[f] = entry.Name
+			}
+		}
+	}
+
+	for _, dir := range []string{"plugins", "components"} {
+		paths, _ := filepath.Glob(filepath.Join(dir, "*"))
+		for _, path := range paths {
+			if !isBinary(path) {
+				continue
+			}
+			symbols, err := binarySymbols(path)
+			if err != nil {
+				continue
+			}
+			slash := filepath.ToSlash(path)
+			b := pluginBinary{name: owners[slash], file: slash, component: dir == "components", symbols: symbols}
+			if b.name == "" {
+				b.name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+			}
+			a.binaries = append(a.binaries, b)
+		}
+	}
+	return a
+}
+
+// provider names the plugin that registers n, how that was found, and
+// whether it is an open.mp component; the name is "" when nothing does
+func (a *attribution) provider(n native) (string, string, bool) {
+	if name := a.includes[filepath.ToSlash(n.file)]; name != "" {
+		return name, "lock", a.isComponent(name)
+	}
+	for _, b := range a.binaries {
+		if b.symbols[n.symbol] {
+			return b.name, "binary", b.component
+		}
+	}
+	base := includeName(n.file)
+	for _, p := range Catalogue() {
+		if strings.EqualFold(p.Name, base) {
+			return p.Name, "index", a.isComponent(p.Name)
+		}
+		for _, inc := range p.Includes {
+			if strings.EqualFold(inc, base) {
+				return p.Name, "index", a.isComponent(p.Name)
+			}
+		}
+	}
+	return "", "", false
+}
+
+func (a *attribution) isComponent(name string) bool {
+	for _, b := range a.binaries {
+		if b.name == name && b.component {
+			return true
+		}
+	}
+	return false
+}
+
+// configName turns a plugins line of the server config into the plugin
+// name the rest of the report uses: the fpawn.lock or index name when the
+// binary is known, else the line without its extension
+func (a *attribution) configName(entry string) string {
+	base := strings.TrimSuffix(strings.TrimSuffix(filepath.Base(entry), ".so"), ".dll")
+	for _, b := range a.binaries {
+		if !b.component && strings.EqualFold(strings.TrimSuffix(filepath.Base(b.file), filepath.Ext(b.file)), base) {
+			return b.name
+		}
+	}
+	for _, p := range Catalogue() {
+		if strings.EqualFold(p.Name, base) {
+			return p.Name
+		}
+	}
+	return base
+}
+
+func isServerInclude(path string) bool {
+	base := strings.ToLower(includeName(path))
+	return serverIncludes[base] || strings.HasPrefix(base, "omp_")
+}
+
+// includeName is the name an include file is included by
+func includeName(path string) string {
+	base := filepath.Base(path)
+	for _, ext := range []string{".inc", ".pawn", ".p", ".pwn"} {
+		if strings.HasSuffix(base, ext) {
+			return strings.TrimSuffix(base, ext)
+		}
+	}
+	return base
+}
+
+// blankPawn replaces comments and string and character literals with
+// spaces, keeping line breaks so lines still line up
+func blankPawn(src string) string {
+	out := []byte(src)
+	const (
+		code = iota
+		line
+		block
+		str
+		char
+	)
+	state := code
+	for i := 0; i < len(out); i++ {
+		c := out[i]
+		var next byte
+		if i+1 < len(out) {
+			next = out[i+1]
+		}
+		switch state {
+		case code:
+			switch {
+			case c == '/' && next == '/':
+				state = line
+			case c == '/' && next == '*':
+				state = block
+			case c == '"':
+				state = str
+			case c == '\'':
+				state = char
+			default:
+				continue
+			}
+			out[i] = ' '
+			if state == line || state == block {
+				out[i+1] = ' '
+				i++
+			}
+		case line:
+			if c == '\n' {
+				state = code
+				continue
+			}
+			out[i] = ' '
+		case block:
+			if c == '*' && next == '/' {
+				out[i], out[i+1] = ' ', ' '
+				i++
+				state = code
+				continue
+			}
+			if c != '\n' {
+				out[i] = ' '
+			}
+		case str, char:
+			quote := byte('"')
+			if state == char {
+				quote = '\''
+			}
+			switch {
+			case c == '\\' && next != '\n' && next != 0:
+				out[i], out[i+1] = ' ', ' '
+				i++
+			case c == quote:
+				out[i] = ' '
+				state = code
+			case c == '\n':
+				// Unterminated literal; the compiler would have stopped here
+				state = code
+			default:
+				out[i] = ' '
+			}
+		}
+	}
+	return string(out)
+}
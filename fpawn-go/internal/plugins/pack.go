@@ -0,0 +1,379 @@
+package plugins
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/FerzDevZ/fpawn/internal/core"
+	"github.com/FerzDevZ/fpawn/internal/servercfg"
+)
+
+// DefaultPack is the archive "fpawn plugins pack" writes when no name is given
+const DefaultPack = "fpawn-plugins.tar.gz"
+
+// packManifest is the file at the root of a pack describing its contents
+const packManifest = "fpawn-pack.json"
+
+// packVersion is bumped when the pack layout changes
+const packVersion = 1
+
+// Pack is a portable set of plugin releases for machines without internet
+// access. The archive holds the manifest and every asset under
+// blobs/<sha256>, exactly as the download cache stores them.
+type Pack struct {
+	Version int       `json:"version"`
+	Created time.Time `json:"created"`
+	// Platform is the OS the assets were chosen for
+	Platform string `json:"platform"`
+	// Plugins are fpawn.lock entries without the project's file lists
+	Plugins []*LockedPlugin `json:"plugins"`
+}
+
+// BuildPack writes the locked plugins named in names, and every locked
+// plugin they depend on, to the archive out; with no names it packs the
+// whole of fpawn.lock. Assets come from the download cache, which is
+// filled from the network first where needed.
+func BuildPack(names []string, out string, c *Client) (*Pack, error) {
+	lock, err := LoadLock()
+	if err != nil {
+		return nil, err
+	}
+	if len(lock.Plugins) == 0 {
+		return nil, fmt.Errorf("%s records no plugins (install them first with: fpawn install <name>)", LockFile)
+	}
+	if len(names) == 0 {
+		for _, entry := range lock.Plugins {
+			names = append(names, entry.Name)
+		}
+	}
+
+	// Pull in dependencies so the pack installs on its own
+	selected := make(map[string]*LockedPlugin)
+	var add func(name, requiredBy string) error
+	add = func(name, requiredBy string) error {
+		if selected[name] != nil {
+			return nil
+		}
+		entry := lock.Find(name)
+		if entry == nil {
+			if requiredBy != "" {
+				return fmt.Errorf("%s, required by %s, is not in %s; install it before packing", name, requiredBy, LockFile)
+			}
+			return fmt.Errorf("%s is not in %s", name, LockFile)
+		}
+		selected[name] = entry
+		if p := GetPluginByName(name); p != nil {
+			for _, dep := range p.Deps {
+				depName, _, err := ParseDep(dep)
+				if err != nil {
+					continue
+				}
+				if err := add(depName, name); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	for _, name := range names {
+		if err := add(name, ""); err != nil {
+			return nil, err
+		}
+	}
+
+	pack := &Pack{Version: packVersion, Created: time.Now().UTC(), Platform: runtime.GOOS}
+	for _, entry := range selected {
+		if !isURL(entry.URL) {
+			return nil, fmt.Errorf("%s was installed from %s, which a pack cannot carry; vendor its files with the project instead", entry.Name, lockedSource(entry))
+		}
+		if _, sum, err := c.fetchAsset(entry.URL, entry.SHA256); err != nil {
+			return nil, fmt.Errorf("%s: %v", entry.Name, err)
+		} else if !strings.EqualFold(sum, entry.SHA256) {
+			return nil, fmt.Errorf("checksum mismatch for %s: %s expects %s, got %s", entry.Asset, LockFile, entry.SHA256, sum)
+		}
+		packed := *entry
+		packed.SHA256 = strings.ToLower(entry.SHA256)
+		packed.Files, packed.Hashes = nil, nil
+		pack.Plugins = append(pack.Plugins, &packed)
+	}
+	sort.Slice(pack.Plugins, func(i, j int) bool { return pack.Plugins[i].Name < pack.Plugins[j].Name })
+
+	if err := writePack(pack, out); err != nil {
+		os.Remove(out)
+		return nil, err
+	}
+	return pack, nil
+}
+
+func writePack(pack *Pack, out string) error {
+	f, err := os.Create(out)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+
+	manifest, err := json.MarshalIndent(pack, "", "  ")
+	if err != nil {
+		return err
+	}
+	hdr := &tar.Header{Name: packManifest, Mode: 0644, Size: int64(len(manifest)), ModTime: pack.Created}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	if _, err := tw.Write(manifest); err != nil {
+		return err
+	}
+
+	written := make(map[string]bool)
+	for _, entry := range pack.Plugins {
+		if written[entry.SHA256] {
+			continue
+		}
+		written[entry.SHA256] = true
+		if err := addBlob(tw, entry.SHA256, pack.Created); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+func addBlob(tw *tar.Writer, sum string, modTime time.Time) error {
+	path, err := blobPath(sum)
+	if err != nil {
+		return err
+	}
+	blob, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer blob.Close()
+	info, err := blob.Stat()
+	if err != nil {
+		return err
+	}
+	hdr := &tar.Header{Name: "blobs/" + sum, Mode: 0644, Size: info.Size(), ModTime: modTime}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	_, err = io.Copy(tw, blob)
+	return err
+}
+
+// ImportPack copies the assets of the pack at archive into the download
+// cache, checking each against its SHA-256, and makes c serve the pack's
+// releases when it is offline
+func ImportPack(archive string, c *Client) (*Pack, error) {
+	f, err := os.Open(archive)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s is not a plugin pack: %v", archive, err)
+	}
+	defer gz.Close()
+
+	var pack *Pack
+	blobs := make(map[string]bool)
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", archive, err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		switch dir, name := path.Split(hdr.Name); {
+		case hdr.Name == packManifest:
+			pack = &Pack{}
+			if err := json.NewDecoder(tr).Decode(pack); err != nil {
+				return nil, fmt.Errorf("%s: %v", packManifest, err)
+			}
+		case dir == "blobs/":
+			if err := storeBlob(tr, name); err != nil {
+				return nil, fmt.Errorf("%s is corrupt: %v", archive, err)
+			}
+			blobs[name] = true
+		}
+	}
+
+	if pack == nil {
+		return nil, fmt.Errorf("%s is not a plugin pack (no %s)", archive, packManifest)
+	}
+	if pack.Version > packVersion {
+		return nil, fmt.Errorf("%s was written by a newer fpawn (version %d)", archive, pack.Version)
+	}
+	if pack.Platform != runtime.GOOS {
+		return nil, fmt.Errorf("%s was packed for %s; its plugins do not run on %s", archive, pack.Platform, runtime.GOOS)
+	}
+	for _, entry := range pack.Plugins {
+		if err := entry.validate(); err != nil {
+			return nil, fmt.Errorf("%s: %v", archive, err)
+		}
+	}
+
+	if c.releases == nil {
+		c.releases = make(map[string][]GitHubRelease)
+	}
+	for _, entry := range pack.Plugins {
+		if _, _, ok := cachedAsset("", entry.SHA256); !blobs[entry.SHA256] && !ok {
+			return nil, fmt.Errorf("%s is missing the asset of %s", archive, entry.Name)
+		}
+		rememberURL(entry.URL, entry.SHA256)
+		release := GitHubRelease{TagName: entry.Tag}
+		if entry.Tag != "" {
+			// Include files fetched from a branch have no release asset;
+			// the install finds them by URL instead
+			release.Assets = []Asset{{Name: entry.Asset, DownloadURL: entry.URL}}
+		}
+		c.releases[entry.Repo] = append(c.releases[entry.Repo], release)
+	}
+	return pack, nil
+}
+
+// RestorePack installs every plugin of pack into a project whose fpawn.lock
+// records none, as on a fresh machine given only the pack. Each is checked
+// against the pack's SHA-256, recorded in the lock and, when it placed a
+// binary, loaded in server.cfg after its dependencies.
+func RestorePack(pack *Pack, c *Client) ([]RestoredPlugin, error) {
+	lock, err := LoadLock()
+	if err != nil {
+		return nil, err
+	}
+	if len(lock.Plugins) > 0 {
+		return nil, fmt.Errorf("%s already records plugins; restore them with: fpawn install --offline", LockFile)
+	}
+
+	cfg, err := servercfg.Open()
+	if err != nil && !errors.Is(err, servercfg.ErrNotFound) {
+		return nil, err
+	}
+	loaded := false
+	var cfgErr error
+
+	var results []RestoredPlugin
+	for _, packed := range packOrder(pack) {
+		entry := *packed
+		r := RestoredPlugin{Name: entry.Name, Tag: entry.Tag, Status: "restored"}
+		if err := fetchLocked(&entry, c); err != nil {
+			r.Status, r.Error = "failed", err.Error()
+			results = append(results, r)
+			continue
+		}
+		lock.Put(&entry)
+		if cfg != nil && entry.hasBinary() {
+			located := GetPluginByName(entry.Name) == nil || lockedSource(&entry) != nil
+			for _, name := range loadNames(&entry, located) {
+				added, err := cfg.AddPlugin(name)
+				if err != nil && cfgErr == nil {
+					cfgErr = fmt.Errorf("%s: %v", cfg.Path, err)
+				}
+				loaded = added || loaded
+			}
+		}
+		results = append(results, r)
+	}
+
+	if err := lock.Save(); err != nil {
+		return results, err
+	}
+	if cfgErr != nil {
+		return results, cfgErr
+	}
+	if loaded {
+		if err := cfg.Save(); err != nil {
+			return results, err
+		}
+	}
+	return results, nil
+}
+
+// InstallPack installs the plugins of pack into a project without a lock
+// and prints what was done
+func InstallPack(pack *Pack, c *Client) ([]RestoredPlugin, error) {
+	fmt.Printf("\n %s %s\n", core.LBlue("📦"), core.Bold("Installing plugins from pack"))
+	fmt.Println(" ──────────────────────────────────────────────────")
+
+	results, err := RestorePack(pack, c)
+	if err != nil {
+		return results, err
+	}
+	return results, printRestored(results)
+}
+
+// packOrder lists the plugins of pack with each one's dependencies first,
+// the order server.cfg must load them in
+func packOrder(pack *Pack) []*LockedPlugin {
+	byName := make(map[string]*LockedPlugin)
+	for _, entry := range pack.Plugins {
+		byName[entry.Name] = entry
+	}
+	var order []*LockedPlugin
+	seen := make(map[string]bool)
+	var visit func(name string)
+	visit = func(name string) {
+		entry := byName[name]
+		if entry == nil || seen[name] {
+			return
+		}
+		seen[name] = true
+		if p := GetPluginByName(name); p != nil {
+			for _, dep := range p.Deps {
+				if depName, _, err := ParseDep(dep); err == nil {
+					visit(depName)
+				}
+			}
+		}
+		order = append(order, entry)
+	}
+	for _, entry := range pack.Plugins {
+		visit(entry.Name)
+	}
+	return order
+}
+
+// PackPlugins builds a pack and prints what went into it
+func PackPlugins(names []string, out string, c *Client) (*Pack, error) {
+	fmt.Printf("\n %s %s\n", core.LBlue("📦"), core.Bold("Packing plugins for offline install"))
+	fmt.Println(" ──────────────────────────────────────────────────")
+
+	pack, err := BuildPack(names, out, c)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range pack.Plugins {
+		tag := entry.Tag
+		if tag == "" {
+			tag = "(include)"
+		}
+		fmt.Printf(" %s %-20s %-12s %s\n", core.Green("✓"), entry.Name, tag, entry.Asset)
+	}
+	fmt.Println(" ──────────────────────────────────────────────────")
+	fmt.Printf(" %s %d plugin(s) written to %s\n", core.Green("✓"), len(pack.Plugins), out)
+	fmt.Printf(" %s Install on the target with: fpawn install --offline --from %s [name...]\n", core.Cyan("[Tip]"), out)
+	return pack, nil
+}
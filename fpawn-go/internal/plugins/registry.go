@@ -0,0 +1,320 @@
+package plugins
+
+import (
+	"crypto/sha1"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/FerzDevZ/fpawn/internal/core"
+)
+
+// builtinIndex is the official index as of this release, used until the
+// published one has been fetched and whenever it cannot be
+//
+//go:embed index.json
+var builtinIndex []byte
+
+// indexVersion is the newest index layout this fpawn understands
+const indexVersion = 1
+
+// registryTTL is how long a fetched index is used before it is revalidated
+const registryTTL = 6 * time.Hour
+
+// Index is a plugin catalogue published by a registry
+type Index struct {
+	Version int      `json:"version"`
+	Name    string   `json:"name"`
+	Plugins []Plugin `json:"plugins"`
+}
+
+// RegistryStatus describes one registry after loading
+type RegistryStatus struct {
+	Source  string `json:"source"`
+	Name    string `json:"name"`
+	Plugins int    `json:"plugins"`
+	// Origin is "file", "network", "cache" or "builtin"
+	Origin string `json:"origin"`
+	Error  string `json:"error,omitempty"`
+}
+
+var (
+	loadOnce  sync.Once
+	catalogue []Plugin
+	statuses  []RegistryStatus
+	// offline keeps remote indexes to their cached or built-in copies
+	offline bool
+)
+
+// SetOffline stops the catalogue from fetching remote indexes, as for
+// --offline. It must be called before the catalogue is first used.
+func SetOffline(on bool) {
+	offline = on
+}
+
+// OfficialRegistry is the index published in the fpawn repository
+func OfficialRegistry() string {
+	owner, name := "FerzDevZ", "fpawn"
+	if core.AppConfig != nil {
+		owner, name = core.AppConfig.RepoOwner, core.AppConfig.RepoName
+	}
+	return fmt.Sprintf("%s/%s/%s/main/fpawn-go/internal/plugins/index.json", DefaultRaw, owner, name)
+}
+
+// Registries returns the sources to read, highest priority first: the
+// REGISTRIES setting in order, then the official index
+func Registries() []string {
+	var sources []string
+	if core.AppConfig != nil {
+		for _, s := range strings.Split(core.AppConfig.Registries, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				sources = append(sources, s)
+			}
+		}
+	}
+	return append(sources, OfficialRegistry())
+}
+
+// Catalogue returns the merged index of every registry, loading it on
+// first use
+func Catalogue() []Plugin {
+	loadOnce.Do(func() {
+		catalogue, statuses = LoadRegistries(Registries(), false)
+	})
+	return catalogue
+}
+
+// LoadRegistries reads every source and merges them. When two registries
+// list the same plugin name, the one earlier in sources wins. refresh
+// revalidates cached indexes even if they are still fresh.
+func LoadRegistries(sources []string, refresh bool) ([]Plugin, []RegistryStatus) {
+	var merged []Plugin
+	var report []RegistryStatus
+	seen := make(map[string]bool)
+
+	for _, source := range sources {
+		status := RegistryStatus{Source: source}
+		idx, origin, err := loadIndex(source, refresh)
+		status.Origin = origin
+		if err != nil {
+			status.Error = err.Error()
+			report = append(report, status)
+			continue
+		}
+
+		status.Name = idx.Name
+		for _, p := range idx.Plugins {
+			if p.Name == "" || seen[p.Name] {
+				continue
+			}
+			seen[p.Name] = true
+			p.Registry = idx.Name
+			merged = append(merged, p)
+			status.Plugins++
+		}
+		report = append(report, status)
+	}
+	return merged, report
+}
+
+// RegistryReport returns how each registry was loaded
+func RegistryReport() []RegistryStatus {
+	Catalogue()
+	return statuses
+}
+
+// RefreshRegistries revalidates every registry and replaces the catalogue
+func RefreshRegistries() []RegistryStatus {
+	Catalogue()
+	catalogue, statuses = LoadRegistries(Registries(), true)
+	return statuses
+}
+
+func loadIndex(source string, refresh bool) (*Index, string, error) {
+	if isURL(source) {
+		return fetchIndex(source, refresh)
+	}
+	data, err := os.ReadFile(source)
+	if err != nil {
+		return nil, "file", err
+	}
+	idx, err := parseIndex(data, source)
+	return idx, "file", err
+}
+
+// fetchIndex returns a remote index, served from the cache while it is
+// fresh and revalidated with its ETag after that. An unreachable registry
+// falls back to the last cached copy, and the official one to the copy
+// built into fpawn. Offline, the network is never tried.
+func fetchIndex(url string, refresh bool) (*Index, string, error) {
+	cachePath := registryCachePath(url)
+	cached, cacheErr := os.ReadFile(cachePath)
+	if cacheErr == nil && !refresh {
+		if info, err := os.Stat(cachePath); err == nil && time.Since(info.ModTime()) < registryTTL {
+			idx, err := parseIndex(cached, url)
+			return idx, "cache", err
+		}
+	}
+
+	fallback := func(err error) (*Index, string, error) {
+		if cacheErr == nil {
+			idx, perr := parseIndex(cached, url)
+			return idx, "cache", perr
+		}
+		if url == OfficialRegistry() {
+			idx, perr := parseIndex(builtinIndex, "builtin")
+			return idx, "builtin", perr
+		}
+		return nil, "network", err
+	}
+	if offline {
+		return fallback(fmt.Errorf("%s is not cached and fpawn is offline", url))
+	}
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, "network", err
+	}
+	if etag, err := os.ReadFile(cachePath + ".etag"); err == nil && cacheErr == nil {
+		req.Header.Set("If-None-Match", strings.TrimSpace(string(etag)))
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fallback(err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		now := time.Now()
+		os.Chtimes(cachePath, now, now)
+		idx, err := parseIndex(cached, url)
+		return idx, "cache", err
+	case http.StatusOK:
+	default:
+		return fallback(fmt.Errorf("registry returned status %d", resp.StatusCode))
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fallback(err)
+	}
+	idx, err := parseIndex(data, url)
+	if err != nil {
+		return fallback(err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(cachePath), 0755); err == nil {
+		os.WriteFile(cachePath, data, 0644)
+		if etag := resp.Header.Get("ETag"); etag != "" {
+			os.WriteFile(cachePath+".etag", []byte(etag), 0644)
+		} else {
+			os.Remove(cachePath + ".etag")
+		}
+	}
+	return idx, "network", nil
+}
+
+func parseIndex(data []byte, source string) (*Index, error) {
+	var idx Index
+	if err := json.Unmarshal(data, &idx); err != nil {
+		return nil, fmt.Errorf("%s: %v", source, err)
+	}
+	if idx.Version < 1 || idx.Version > indexVersion {
+		return nil, fmt.Errorf("%s: unsupported index version %d (this fpawn reads up to %d)", source, idx.Version, indexVersion)
+	}
+	if idx.Name == "" {
+		idx.Name = source
+	}
+	return &idx, nil
+}
+
+// registryCachePath keys the cached copy of an index by its URL
+func registryCachePath(url string) string {
+	return filepath.Join(cacheDir(), "registry", fmt.Sprintf("%x.json", sha1.Sum([]byte(url))))
+}
+
+func isURL(source string) bool {
+	return strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://")
+}
+
+// AddRegistry puts source at the front of REGISTRIES, so it outranks the
+// registries already configured. Local paths are stored absolute.
+func AddRegistry(source string) error {
+	if !isURL(source) {
+		abs, err := filepath.Abs(source)
+		if err != nil {
+			return err
+		}
+		if _, _, err := loadIndex(abs, false); err != nil {
+			return err
+		}
+		source = abs
+	}
+	for _, s := range Registries() {
+		if s == source {
+			return fmt.Errorf("%s is already a registry", source)
+		}
+	}
+
+	sources := []string{source}
+	if core.AppConfig.Registries != "" {
+		sources = append(sources, core.AppConfig.Registries)
+	}
+	core.AppConfig.Registries = strings.Join(sources, ",")
+	return core.SaveConfig()
+}
+
+// RemoveRegistry drops source from REGISTRIES
+func RemoveRegistry(source string) error {
+	if abs, err := filepath.Abs(source); err == nil && !isURL(source) {
+		source = abs
+	}
+	var kept []string
+	found := false
+	for _, s := range strings.Split(core.AppConfig.Registries, ",") {
+		if s = strings.TrimSpace(s); s == "" {
+			continue
+		}
+		if s == source {
+			found = true
+			continue
+		}
+		kept = append(kept, s)
+	}
+	if !found {
+		return fmt.Errorf("%s is not a configured registry", source)
+	}
+	core.AppConfig.Registries = strings.Join(kept, ",")
+	return core.SaveConfig()
+}
+
+// ShowRegistries prints every registry and how it was loaded
+func ShowRegistries(refresh bool) []RegistryStatus {
+	fmt.Printf("\n %s %s\n", core.LBlue("📚"), core.Bold("Plugin Registries"))
+	fmt.Println(" ──────────────────────────────────────────────────")
+
+	report := RegistryReport()
+	if refresh {
+		report = RefreshRegistries()
+	}
+	for i, s := range report {
+		if s.Error != "" {
+			fmt.Printf(" %d. %s %s\n    %s\n", i+1, core.Red("✗"), s.Source, core.Red(s.Error))
+			continue
+		}
+		fmt.Printf(" %d. %s %-12s %3d plugins  %s\n    %s\n", i+1, core.Green("✓"), s.Name, s.Plugins, core.Cyan("("+s.Origin+")"), s.Source)
+	}
+	fmt.Println(" ──────────────────────────────────────────────────")
+	fmt.Printf(" %s %d plugins; earlier registries win on duplicate names\n", core.Cyan("[Info]"), len(Catalogue()))
+	return report
+}
@@ -0,0 +1,330 @@
+package plugins
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/FerzDevZ/fpawn/internal/compiler"
+	"github.com/FerzDevZ/fpawn/internal/manifest"
+)
+
+// Ecosystems a project can target, as compared with Plugin.Compat
+const (
+	EcosystemOpenMP = "open.mp"
+	EcosystemSAMP   = "SA-MP"
+)
+
+// PlanStep is one plugin in an install plan
+type PlanStep struct {
+	Name string `json:"name"`
+	Tag  string `json:"tag"`
+	// Action is "install", or "keep" for a locked plugin that already
+	// satisfies every constraint
+	Action     string   `json:"action"`
+	Constraint string   `json:"constraint"`
+	RequiredBy []string `json:"required_by,omitempty"`
+	Compat     string   `json:"compat"`
+	// Source is where a plugin given by location comes from; empty for
+	// index plugins
+	Source string `json:"source,omitempty"`
+
+	source *Source
+}
+
+// Plan is a resolved dependency graph, dependencies before dependents
+type Plan struct {
+	Ecosystem string     `json:"ecosystem"`
+	Steps     []PlanStep `json:"steps"`
+	Warnings  []string   `json:"warnings"`
+}
+
+// requirement is one constraint placed on a plugin and who placed it
+type requirement struct {
+	from       string
+	constraint Constraint
+}
+
+// resolver walks the dependency graph of the requested plugins
+type resolver struct {
+	client *Client
+	lock   *Lock
+
+	// state is 1 while a plugin's dependencies are being walked, 2 after
+	state    map[string]int
+	path     []string
+	order    []string
+	required map[string][]requirement
+	problems []string
+	warnings []string
+}
+
+// Resolve builds the install plan for specs ("name" or "name@constraint").
+// Cycles, unknown plugins, plugins built for the other ecosystem (unless
+// force is set) and constraints no release satisfies are all reported
+// together before anything is downloaded.
+func Resolve(specs []string, c *Client, lock *Lock, force bool) (*Plan, error) {
+	r := &resolver{
+		client:   c,
+		lock:     lock,
+		state:    make(map[string]int),
+		required: make(map[string][]requirement),
+	}
+	plan := &Plan{Ecosystem: DetectEcosystem(), Warnings: []string{}}
+
+	for _, spec := range specs {
+		name, constraint, err := ParseDep(spec)
+		if err != nil {
+			r.problems = append(r.problems, err.Error())
+			continue
+		}
+		r.required[name] = append(r.required[name], requirement{"", constraint})
+		r.visit(name)
+	}
+
+	for _, name := range r.order {
+		plugin := GetPluginByName(name)
+		if !compatible(plugin.Compat, plan.Ecosystem) {
+			msg := fmt.Sprintf("%s is built for %s but the project targets %s", name, compatName(plugin.Compat), plan.Ecosystem)
+			if !force {
+				r.problems = append(r.problems, msg+" (use --force to install it anyway)")
+				continue
+			}
+			plan.Warnings = append(plan.Warnings, msg)
+		}
+		if step, ok := r.pick(plugin); ok {
+			plan.Steps = append(plan.Steps, step)
+		}
+	}
+
+	plan.Warnings = append(plan.Warnings, r.warnings...)
+	if len(r.problems) > 0 {
+		return nil, fmt.Errorf("cannot resolve dependencies:\n   - %s", strings.Join(r.problems, "\n   - "))
+	}
+	return plan, nil
+}
+
+// PlanInstall builds the install plan for a mix of index specs and plugins
+// given by location (see ParseSource). Located plugins declare no
+// dependencies, so they are installed first, and one named like an index
+// plugin takes its place for everything that depends on it.
+func PlanInstall(specs []string, c *Client, lock *Lock, force bool) (*Plan, error) {
+	var named []string
+	var located []PlanStep
+	var problems []string
+	for _, spec := range specs {
+		src, ok, err := ParseSource(spec)
+		switch {
+		case err != nil:
+			problems = append(problems, err.Error())
+		case ok:
+			located = append(located, PlanStep{Name: src.Name, Tag: src.Ref, Action: "install", Constraint: "*", Source: src.String(), source: src})
+		default:
+			named = append(named, spec)
+		}
+	}
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("cannot resolve dependencies:\n   - %s", strings.Join(problems, "\n   - "))
+	}
+
+	plan := &Plan{Ecosystem: DetectEcosystem(), Steps: []PlanStep{}, Warnings: []string{}}
+	if len(named) > 0 {
+		var err error
+		if plan, err = Resolve(named, c, lock, force); err != nil {
+			return nil, err
+		}
+	}
+	replaced := make(map[string]bool)
+	for _, step := range located {
+		replaced[step.Name] = true
+	}
+	steps := located
+	for _, step := range plan.Steps {
+		if !replaced[step.Name] {
+			steps = append(steps, step)
+			continue
+		}
+		if step.Action == "install" && len(step.RequiredBy) > 0 {
+			plan.Warnings = append(plan.Warnings, fmt.Sprintf("%s, required by %s, is installed from its source instead of the index", step.Name, strings.Join(step.RequiredBy, ", ")))
+		}
+	}
+	plan.Steps = steps
+	return plan, nil
+}
+
+// visit walks name's dependencies depth first and appends name to the
+// order after them
+func (r *resolver) visit(name string) {
+	switch r.state[name] {
+	case 2:
+		return
+	case 1:
+		start := 0
+		for i, n := range r.path {
+			if n == name {
+				start = i
+			}
+		}
+		cycle := append(append([]string{}, r.path[start:]...), name)
+		r.problems = append(r.problems, "dependency cycle: "+strings.Join(cycle, " → "))
+		return
+	}
+
+	plugin := GetPluginByName(name)
+	if plugin == nil {
+		msg := fmt.Sprintf("plugin '%s' not found in database", name)
+		if len(r.path) > 0 {
+			msg += fmt.Sprintf(" (required by %s)", r.path[len(r.path)-1])
+		}
+		r.problems = append(r.problems, msg)
+		r.state[name] = 2
+		return
+	}
+
+	r.state[name] = 1
+	r.path = append(r.path, name)
+	for _, dep := range plugin.Deps {
+		depName, constraint, err := ParseDep(dep)
+		if err != nil {
+			r.problems = append(r.problems, fmt.Sprintf("%s: %v", name, err))
+			continue
+		}
+		r.required[depName] = append(r.required[depName], requirement{name, constraint})
+		r.visit(depName)
+	}
+	r.path = r.path[:len(r.path)-1]
+	r.state[name] = 2
+	r.order = append(r.order, name)
+}
+
+// pick chooses the release of plugin that satisfies every requirement on
+// it, keeping a locked release when it does
+func (r *resolver) pick(plugin *Plugin) (PlanStep, bool) {
+	reqs := r.required[plugin.Name]
+	step := PlanStep{Name: plugin.Name, Action: "install", Compat: plugin.Compat}
+	var constraints []string
+	for _, req := range reqs {
+		if req.from != "" {
+			step.RequiredBy = append(step.RequiredBy, req.from)
+		}
+		if !req.constraint.Any() {
+			constraints = append(constraints, req.constraint.String())
+		}
+	}
+	step.Constraint = strings.Join(constraints, ", ")
+	if step.Constraint == "" {
+		step.Constraint = "*"
+	}
+
+	allows := func(tag string) bool {
+		for _, req := range reqs {
+			if !req.constraint.Allows(tag) {
+				return false
+			}
+		}
+		return true
+	}
+
+	if locked := r.lock.Find(plugin.Name); locked != nil && locked.Present() {
+		if allows(locked.Tag) {
+			step.Tag, step.Action = locked.Tag, "keep"
+			return step, true
+		}
+		r.problems = append(r.problems, fmt.Sprintf("%s is locked at %s, which does not satisfy %s (%s); move it with: fpawn upgrade %s --tag <release>",
+			plugin.Name, locked.Tag, step.Constraint, describe(reqs), plugin.Name))
+		return step, false
+	}
+
+	if len(step.RequiredBy) > 0 && len(constraints) == 0 && isPluginInstalled(r.lock, plugin.Name) {
+		// Placed by hand: it satisfies an unconstrained dependency, but
+		// nothing records which release it is
+		step.Action = "keep"
+		r.warnings = append(r.warnings, fmt.Sprintf("%s was installed by hand and is not in %s", plugin.Name, LockFile))
+		return step, true
+	}
+
+	repo := extractRepoPath(plugin.URL)
+	if repo == "" {
+		// A git repository outside GitHub: its default branch is installed
+		if len(constraints) > 0 {
+			r.problems = append(r.problems, fmt.Sprintf("%s is hosted at %s, which has no releases to satisfy %s", plugin.Name, plugin.URL, step.Constraint))
+			return step, false
+		}
+		return step, true
+	}
+	if len(constraints) == 0 {
+		release, err := r.client.LatestRelease(repo)
+		if err != nil {
+			r.problems = append(r.problems, fmt.Sprintf("%s: %v", plugin.Name, err))
+			return step, false
+		}
+		step.Tag = release.TagName
+		return step, true
+	}
+
+	releases, err := r.client.Releases(repo)
+	if err != nil {
+		r.problems = append(r.problems, fmt.Sprintf("%s: %v", plugin.Name, err))
+		return step, false
+	}
+	var best version
+	for _, rel := range releases {
+		v, ok := parseVersion(rel.TagName)
+		if !ok || !allows(rel.TagName) {
+			continue
+		}
+		if best == nil || v.compare(best) > 0 {
+			best, step.Tag = v, rel.TagName
+		}
+	}
+	if step.Tag == "" {
+		r.problems = append(r.problems, fmt.Sprintf("%s: no release satisfies %s (%s)", plugin.Name, step.Constraint, describe(reqs)))
+		return step, false
+	}
+	return step, true
+}
+
+// describe lists who asked for what, for conflict messages
+func describe(reqs []requirement) string {
+	var parts []string
+	for _, req := range reqs {
+		from := req.from
+		if from == "" {
+			from = "requested"
+		}
+		parts = append(parts, fmt.Sprintf("%s from %s", req.constraint, from))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// DetectEcosystem reports whether the project targets open.mp or SA-MP,
+// trusting the manifest first and then the compiler profile detection
+func DetectEcosystem() string {
+	if m := manifest.Current(); m != nil {
+		if m.IsOpenMP() {
+			return EcosystemOpenMP
+		}
+		return EcosystemSAMP
+	}
+	if compiler.DetectProfile() == compiler.ProfilePawno {
+		return EcosystemSAMP
+	}
+	return EcosystemOpenMP
+}
+
+// compatible checks a Plugin.Compat value against an ecosystem
+func compatible(compat, ecosystem string) bool {
+	switch compat {
+	case "Legacy":
+		return ecosystem == EcosystemSAMP
+	case "OMP":
+		return ecosystem == EcosystemOpenMP
+	}
+	return true
+}
+
+func compatName(compat string) string {
+	if compat == "Legacy" {
+		return EcosystemSAMP + " only"
+	}
+	return EcosystemOpenMP + " only"
+}
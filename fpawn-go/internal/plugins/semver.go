@@ -0,0 +1,166 @@
+package plugins
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// version is the numeric part of a release tag: "v2.13.8" is [2 13 8]
+type version []int
+
+// parseVersion reads a release tag, skipping a leading "v" or other letters
+// ("R41-4" is 41.4). ok is false when the tag has no numbers.
+func parseVersion(tag string) (version, bool) {
+	s := strings.TrimLeftFunc(tag, func(r rune) bool { return !unicode.IsDigit(r) })
+	var v version
+	for _, part := range strings.FieldsFunc(s, func(r rune) bool { return r == '.' || r == '-' }) {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			// Pre-release and build suffixes end the version
+			break
+		}
+		v = append(v, n)
+	}
+	return v, len(v) > 0
+}
+
+// compare orders versions, treating missing parts as zero
+func (v version) compare(o version) int {
+	for i := 0; i < len(v) || i < len(o); i++ {
+		var a, b int
+		if i < len(v) {
+			a = v[i]
+		}
+		if i < len(o) {
+			b = o[i]
+		}
+		if a != b {
+			if a < b {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// bump returns the smallest version above every version sharing the
+// first n parts of v: bump of 2.13 at 1 is 3.0
+func (v version) bump(n int) version {
+	next := make(version, n)
+	copy(next, v)
+	next[n-1]++
+	return next
+}
+
+type bound struct {
+	op string
+	v  version
+}
+
+// Constraint limits the releases a dependency may resolve to. It accepts
+// "^2.13", "~2.13.1", ">=2.0 <3", "2.13" (any 2.13.x) and "*".
+type Constraint struct {
+	raw    string
+	bounds []bound
+}
+
+// ParseConstraint reads a constraint; "" and "*" allow every release
+func ParseConstraint(s string) (Constraint, error) {
+	c := Constraint{raw: strings.TrimSpace(s)}
+	for _, term := range strings.FieldsFunc(c.raw, func(r rune) bool { return r == ',' || unicode.IsSpace(r) }) {
+		if term == "*" {
+			continue
+		}
+		op := ""
+		for _, prefix := range []string{">=", "<=", ">", "<", "=", "^", "~"} {
+			if strings.HasPrefix(term, prefix) {
+				op, term = prefix, strings.TrimPrefix(term, prefix)
+				break
+			}
+		}
+		v, ok := parseVersion(term)
+		if !ok {
+			return Constraint{}, fmt.Errorf("invalid version constraint %q", s)
+		}
+
+		switch op {
+		case "^":
+			// Compatible with v: same major, or same minor below 1.0
+			n := 1
+			if v[0] == 0 && len(v) > 1 {
+				n = 2
+			}
+			c.bounds = append(c.bounds, bound{">=", v}, bound{"<", v.bump(n)})
+		case "~":
+			// Same minor, or same major when only a major is given
+			n := 2
+			if len(v) == 1 {
+				n = 1
+			}
+			c.bounds = append(c.bounds, bound{">=", v}, bound{"<", v.bump(n)})
+		case "", "=":
+			c.bounds = append(c.bounds, bound{"=", v})
+		default:
+			c.bounds = append(c.bounds, bound{op, v})
+		}
+	}
+	return c, nil
+}
+
+// Any reports whether the constraint allows every release
+func (c Constraint) Any() bool {
+	return len(c.bounds) == 0
+}
+
+// Allows reports whether the release tagged tag satisfies the constraint.
+// Tags without a version only satisfy a constraint that allows anything.
+func (c Constraint) Allows(tag string) bool {
+	if c.Any() {
+		return true
+	}
+	v, ok := parseVersion(tag)
+	if !ok {
+		return false
+	}
+	for _, b := range c.bounds {
+		var ok bool
+		switch b.op {
+		case "=":
+			// "2.13" pins the parts it names and leaves the rest free
+			ok = len(v) >= len(b.v) && v[:len(b.v)].compare(b.v) == 0
+		case ">=":
+			ok = v.compare(b.v) >= 0
+		case ">":
+			ok = v.compare(b.v) > 0
+		case "<=":
+			ok = v.compare(b.v) <= 0
+		case "<":
+			ok = v.compare(b.v) < 0
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func (c Constraint) String() string {
+	if c.raw == "" {
+		return "*"
+	}
+	return c.raw
+}
+
+// ParseDep splits a dependency such as "sscanf@^2.13" into the plugin name
+// and its constraint
+func ParseDep(spec string) (string, Constraint, error) {
+	name, raw, _ := strings.Cut(spec, "@")
+	c, err := ParseConstraint(raw)
+	if err != nil {
+		return "", Constraint{}, fmt.Errorf("%s: %v", spec, err)
+	}
+	return strings.TrimSpace(name), c, nil
+}
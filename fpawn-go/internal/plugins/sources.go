@@ -0,0 +1,487 @@
+package plugins
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/FerzDevZ/fpawn/internal/core"
+)
+
+// Kinds of Source
+const (
+	SourceGitHub = "github" // github:owner/repo@tag, a GitHub release
+	SourceGit    = "git"    // any git repository, cloned
+	SourcePath   = "path"   // a local directory, archive or file
+	SourceURL    = "url"    // an archive or file downloaded as it is
+)
+
+// Source is a plugin given by location instead of by index name
+type Source struct {
+	Name string `json:"name"`
+	Kind string `json:"kind"`
+	// Location is owner/repo, a git URL, a path or a download URL
+	Location string `json:"location"`
+	// Ref is the release tag, or the git branch, tag or commit; empty
+	// means the latest release or the default branch
+	Ref string `json:"ref,omitempty"`
+}
+
+func (s *Source) String() string {
+	switch s.Kind {
+	case SourceGitHub:
+		if s.Ref != "" {
+			return "github:" + s.Location + "@" + s.Ref
+		}
+		return "github:" + s.Location
+	case SourceGit:
+		if s.Ref != "" {
+			return s.Location + "#" + s.Ref
+		}
+	}
+	return s.Location
+}
+
+// pluginName matches the names a plugin can be given with "name=source"
+var pluginName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// commitHash matches a full git object name
+var commitHash = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+// ParseSource reads an install argument that gives a plugin by location:
+//
+//	github:owner/repo[@tag]          a GitHub release
+//	https://host/owner/repo.git[#ref] any git host (also git+<url>, git@host:repo)
+//	https://host/file.zip            an archive, .so/.dll or .inc to download
+//	./vendor/myplugin                a local directory, archive or file
+//
+// "name=<source>" sets the plugin name, which is otherwise taken from the
+// location. ok is false for index names and name@constraint specs.
+func ParseSource(spec string) (*Source, bool, error) {
+	src := &Source{}
+	if i := strings.Index(spec, "="); i > 0 && pluginName.MatchString(spec[:i]) {
+		src.Name, spec = spec[:i], spec[i+1:]
+	}
+
+	switch {
+	case strings.HasPrefix(spec, "github:"):
+		src.Kind, src.Location = SourceGitHub, strings.TrimPrefix(spec, "github:")
+		if i := strings.LastIndex(src.Location, "@"); i >= 0 {
+			src.Location, src.Ref = src.Location[:i], src.Location[i+1:]
+		}
+		if strings.Count(src.Location, "/") != 1 || strings.HasPrefix(src.Location, "/") || strings.HasSuffix(src.Location, "/") {
+			return nil, false, fmt.Errorf("%s: expected github:owner/repo[@tag]", spec)
+		}
+	case strings.HasPrefix(spec, "git+"), strings.HasPrefix(spec, "git@"), strings.HasPrefix(spec, "git://"), strings.HasPrefix(spec, "ssh://"):
+		src.Kind = SourceGit
+		src.Location, src.Ref = splitRef(strings.TrimPrefix(spec, "git+"))
+		if err := checkGitSource(src.Location, src.Ref); err != nil {
+			return nil, false, err
+		}
+	case isURL(spec):
+		loc, ref := splitRef(spec)
+		u, err := url.Parse(loc)
+		if err != nil {
+			return nil, false, fmt.Errorf("%s: %v", spec, err)
+		}
+		repo := extractRepoPath(loc)
+		switch {
+		case assetKind(strings.ToLower(u.Path)) != "":
+			src.Kind, src.Location = SourceURL, spec
+		case repo != "" && !strings.HasSuffix(u.Path, ".git"):
+			src.Kind, src.Location, src.Ref = SourceGitHub, repo, ref
+		default:
+			// Anything else on a web host is taken to be a repository
+			src.Kind, src.Location, src.Ref = SourceGit, loc, ref
+			if err := checkGitSource(loc, ref); err != nil {
+				return nil, false, err
+			}
+		}
+	case isLocalPath(spec):
+		src.Kind, src.Location = SourcePath, spec
+		if strings.HasPrefix(spec, "~/") {
+			if home, err := os.UserHomeDir(); err == nil {
+				src.Location = filepath.Join(home, spec[2:])
+			}
+		}
+		if _, err := os.Stat(src.Location); os.IsNotExist(err) {
+			return nil, false, fmt.Errorf("%s does not exist", spec)
+		} else if err != nil {
+			return nil, false, err
+		}
+	default:
+		return nil, false, nil
+	}
+
+	if src.Name == "" {
+		src.Name = sourceName(src)
+	}
+	if !pluginName.MatchString(src.Name) {
+		return nil, false, fmt.Errorf("%s: cannot tell the plugin name; give it as name=%s", spec, spec)
+	}
+	return src, true, nil
+}
+
+// checkGitSource rejects a repository or ref git would read as an option
+// ("--upload-pack=<cmd>" runs a command on fetch)
+func checkGitSource(repo, ref string) error {
+	if repo == "" {
+		return fmt.Errorf("git source has no repository")
+	}
+	if strings.HasPrefix(repo, "-") {
+		return fmt.Errorf("invalid git repository %q", repo)
+	}
+	if strings.HasPrefix(ref, "-") {
+		return fmt.Errorf("invalid git ref %q", ref)
+	}
+	return nil
+}
+
+// splitRef separates the "#ref" of a git or download URL, also accepting
+// "repo.git@ref"
+func splitRef(loc string) (string, string) {
+	if i := strings.LastIndex(loc, "#"); i >= 0 {
+		return loc[:i], loc[i+1:]
+	}
+	if i := strings.LastIndex(loc, ".git@"); i >= 0 {
+		return loc[:i+4], loc[i+5:]
+	}
+	return loc, ""
+}
+
+func isLocalPath(spec string) bool {
+	for _, prefix := range []string{"./", "../", "~/", ".\\", "..\\"} {
+		if strings.HasPrefix(spec, prefix) {
+			return true
+		}
+	}
+	if filepath.IsAbs(spec) || spec == "." || spec == ".." {
+		return true
+	}
+	// Index names never contain a separator
+	if strings.ContainsAny(spec, `/\`) {
+		_, err := os.Stat(spec)
+		return err == nil
+	}
+	return false
+}
+
+// sourceName derives a plugin name from a location: the repository name,
+// or the file name without its extension, version and platform
+func sourceName(src *Source) string {
+	loc := src.Location
+	if src.Kind == SourcePath {
+		// "." and "../" name the directory they resolve to
+		if abs, err := filepath.Abs(loc); err == nil {
+			loc = abs
+		}
+	}
+	loc = strings.TrimRight(strings.ReplaceAll(loc, "\\", "/"), "/")
+	if u, err := url.Parse(loc); err == nil && u.Scheme != "" && src.Kind != SourcePath {
+		loc = u.Path
+	}
+	loc = strings.TrimSuffix(loc, ".git")
+	if i := strings.LastIndexAny(loc, "/:"); i >= 0 {
+		loc = loc[i+1:]
+	}
+	if src.Kind == SourceGitHub || src.Kind == SourceGit {
+		return loc
+	}
+
+	loc = strings.TrimSuffix(loc, assetKind(strings.ToLower(loc)))
+	var kept []string
+	for _, part := range strings.FieldsFunc(loc, func(r rune) bool { return r == '-' || r == '_' }) {
+		if kept != nil && isVersionOrPlatform(part) {
+			break
+		}
+		kept = append(kept, part)
+	}
+	return strings.Join(kept, "-")
+}
+
+// isVersionOrPlatform reports whether part of a file name is a version
+// ("2.13", "v1", "R41") or a platform ("linux", "win32", "x86")
+func isVersionOrPlatform(part string) bool {
+	lower := strings.ToLower(part)
+	digits := strings.TrimPrefix(strings.TrimPrefix(lower, "v"), "r")
+	if digits != "" && digits[0] >= '0' && digits[0] <= '9' {
+		return true
+	}
+	for _, words := range osTokens {
+		for _, w := range words {
+			if lower == w {
+				return true
+			}
+		}
+	}
+	switch lower {
+	case "x86", "x64", "i386", "i686", "amd64", "omp", "samp":
+		return true
+	}
+	return false
+}
+
+// installSource installs a plugin given by location and returns its lock
+// entry. Downloads and local files go through the same extraction as index
+// plugins; git repositories are cloned and their files placed by the same
+// layout rules.
+func installSource(src *Source, c *Client) (*LockedPlugin, error) {
+	entry := &LockedPlugin{Name: src.Name}
+	switch src.Kind {
+	case SourceGitHub:
+		return installRelease(&Plugin{Name: src.Name, URL: "https://github.com/" + src.Location}, src.Ref, c)
+	case SourceURL:
+		u, _ := url.Parse(src.Location)
+		entry.Asset, entry.URL = path.Base(u.Path), src.Location
+		if _, _, ok := cachedAsset(entry.URL, ""); ok {
+			fmt.Printf(" %s Using cached: %s\n", core.Blue("[Cache]"), entry.Asset)
+		} else {
+			fmt.Printf(" %s Downloading: %s\n", core.Blue("[Download]"), src.Location)
+		}
+	case SourcePath:
+		loc := src.Location
+		if !filepath.IsAbs(loc) {
+			loc = filepath.Clean(loc)
+		}
+		entry.Asset, entry.URL = filepath.Base(loc), "file:"+filepath.ToSlash(loc)
+		fmt.Printf(" %s Copying from %s\n", core.Blue("[Local]"), loc)
+	case SourceGit:
+		entry.Repo, entry.Tag, entry.URL = src.Location, src.Ref, gitSourceURL(src.Location, src.Ref)
+		fmt.Printf(" %s Cloning %s\n", core.Blue("[Git]"), src)
+	default:
+		return nil, fmt.Errorf("unknown source kind %q", src.Kind)
+	}
+	if err := fetchLocked(entry, c); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// lockedSource returns the Source a lock entry was installed from, or nil
+// for index plugins installed from GitHub releases
+func lockedSource(entry *LockedPlugin) *Source {
+	switch {
+	case strings.HasPrefix(entry.URL, "file:"):
+		return &Source{Name: entry.Name, Kind: SourcePath, Location: filepath.FromSlash(strings.TrimPrefix(entry.URL, "file:"))}
+	case strings.HasPrefix(entry.URL, "git+"):
+		return &Source{Name: entry.Name, Kind: SourceGit, Location: entry.Repo, Ref: entry.Tag}
+	case entry.Repo == "":
+		// Index plugins always record their repository
+		return &Source{Name: entry.Name, Kind: SourceURL, Location: entry.URL}
+	}
+	return nil
+}
+
+// fetched is a source made available on disk
+type fetched struct {
+	path string
+	sum  string
+	// dir is set for directory trees, whose sum is a treeHash
+	dir  bool
+	done func()
+}
+
+// fetchSource makes what entry.URL names available on disk: a download
+// from the cache, a local file or directory, or a git checkout. A git
+// entry is pinned to the commit it checked out.
+func (c *Client) fetchSource(entry *LockedPlugin) (*fetched, error) {
+	switch {
+	case strings.HasPrefix(entry.URL, "file:"):
+		loc := filepath.FromSlash(strings.TrimPrefix(entry.URL, "file:"))
+		info, err := os.Stat(loc)
+		if err != nil {
+			return nil, err
+		}
+		f := &fetched{path: loc, dir: info.IsDir(), done: func() {}}
+		if f.dir {
+			f.sum, err = treeHash(loc)
+		} else {
+			f.sum, err = hashFile(loc)
+		}
+		return f, err
+	case strings.HasPrefix(entry.URL, "git+"):
+		if c.Offline {
+			return nil, fmt.Errorf("%s is a git repository and fpawn is offline", entry.Repo)
+		}
+		dir, err := checkoutGit(entry)
+		if err != nil {
+			return nil, err
+		}
+		f := &fetched{path: dir, dir: true, done: func() { os.RemoveAll(dir) }}
+		if f.sum, err = treeHash(dir); err != nil {
+			f.done()
+			return nil, err
+		}
+		return f, nil
+	}
+	path, sum, err := c.fetchAsset(entry.URL, entry.SHA256)
+	if err != nil {
+		return nil, err
+	}
+	return &fetched{path: path, sum: sum, done: func() {}}, nil
+}
+
+// gitSourceURL is how a lock entry records a git source
+func gitSourceURL(repo, ref string) string {
+	if ref == "" {
+		return "git+" + repo
+	}
+	return "git+" + repo + "#" + ref
+}
+
+// checkoutGit fetches the ref of a git+<repo>#<ref> entry into a temporary
+// directory, pins entry.URL to the commit and returns the directory
+func checkoutGit(entry *LockedPlugin) (string, error) {
+	git, err := exec.LookPath("git")
+	if err != nil {
+		return "", fmt.Errorf("installing from %s needs git", entry.Repo)
+	}
+	repo, ref := splitRef(strings.TrimPrefix(entry.URL, "git+"))
+	if err := checkGitSource(repo, ref); err != nil {
+		return "", err
+	}
+	dir, err := os.MkdirTemp("", "fpawn-git-*")
+	if err != nil {
+		return "", err
+	}
+	run := func(args ...string) (string, error) {
+		cmd := exec.Command(git, append([]string{"-C", dir}, args...)...)
+		cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			return "", fmt.Errorf("git %s: %s", args[0], strings.TrimSpace(string(out)))
+		}
+		return strings.TrimSpace(string(out)), nil
+	}
+
+	want := ref
+	if want == "" {
+		want = "HEAD"
+	}
+	_, err = run("init", "-q")
+	if err == nil {
+		_, err = run("fetch", "-q", "--depth", "1", "--", repo, want)
+		if err != nil && commitHash.MatchString(ref) {
+			// Not every server hands out a commit by name; fetch it all
+			_, err = run("fetch", "-q", "--", repo, "+refs/heads/*:refs/remotes/origin/*", "+refs/tags/*:refs/tags/*")
+			want = ref
+		} else {
+			want = "FETCH_HEAD"
+		}
+	}
+	if err == nil {
+		_, err = run("checkout", "-q", want)
+	}
+	var commit string
+	if err == nil {
+		commit, err = run("rev-parse", "HEAD")
+	}
+	if err != nil {
+		os.RemoveAll(dir)
+		return "", err
+	}
+	entry.URL = gitSourceURL(repo, commit)
+	return dir, nil
+}
+
+// treeHash digests a directory: the path and SHA-256 of every file, in
+// order. Git metadata is left out.
+func treeHash(dir string) (string, error) {
+	files, err := treeFiles(dir)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	for _, rel := range files {
+		sum, err := hashFile(filepath.Join(dir, filepath.FromSlash(rel)))
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(h, "%s\x00%s\n", rel, sum)
+	}
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
+
+// treeFiles lists the regular files below dir as sorted slash paths
+func treeFiles(dir string) ([]string, error) {
+	var files []string
+	err := filepath.Walk(dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() && info.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	sort.Strings(files)
+	return files, err
+}
+
+// placeTree copies the files of a directory into the project where
+// layoutPath puts them, as if the directory were an extracted archive
+func placeTree(dir string) ([]string, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	if wd, err := os.Getwd(); err == nil && (abs == wd || strings.HasPrefix(wd, abs+string(filepath.Separator))) {
+		return nil, fmt.Errorf("%s contains the project itself", dir)
+	}
+
+	files, err := treeFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	place := newPlacer()
+	for _, rel := range files {
+		src := filepath.Join(dir, filepath.FromSlash(rel))
+		if dest := layoutPath(rel, runtime.GOOS, place.includeDir); dest != "" && sameFile(src, filepath.FromSlash(dest)) {
+			// Already where it belongs, as when installing ./plugins
+			place.files = append(place.files, dest)
+			continue
+		}
+		f, err := os.Open(src)
+		if err != nil {
+			return place.files, err
+		}
+		info, err := f.Stat()
+		if err == nil {
+			err = place.write(rel, f, info.Mode())
+		}
+		f.Close()
+		if err != nil {
+			return place.files, err
+		}
+	}
+	if len(place.files) == 0 {
+		return nil, fmt.Errorf("%s contains no plugin or include files for %s", dir, runtime.GOOS)
+	}
+	return place.files, nil
+}
+
+// sameFile reports whether a and b are the same existing file
+func sameFile(a, b string) bool {
+	ai, err := os.Stat(a)
+	if err != nil {
+		return false
+	}
+	bi, err := os.Stat(b)
+	return err == nil && os.SameFile(ai, bi)
+}
@@ -0,0 +1,242 @@
+package plugins
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/FerzDevZ/fpawn/internal/core"
+	"github.com/FerzDevZ/fpawn/internal/servercfg"
+)
+
+// Uninstall is the outcome of removing one plugin
+type Uninstall struct {
+	Name    string   `json:"name"`
+	Removed []string `json:"removed"`
+	// Kept lists files the plugin placed that another installed plugin
+	// placed too
+	Kept []string `json:"kept,omitempty"`
+	// Dependents are the installed plugins that depend on this one; they
+	// only allow the removal with force
+	Dependents []string `json:"dependents,omitempty"`
+	Unlocked   bool     `json:"unlocked"`
+	// Config is the server config the plugin was unloaded from
+	Config string `json:"config,omitempty"`
+}
+
+// staged is a file moved aside until the uninstall commits
+type staged struct {
+	path, aside string
+}
+
+// RemovePlugin uninstalls name without printing anything. It removes
+// exactly the files fpawn.lock records for it (or the usual names of a
+// plugin placed by hand), drops its lock entry and unloads it from the
+// server config. Files are moved aside first and the lock and config are
+// snapshotted, so a failure at any step puts everything back.
+func RemovePlugin(name string, force bool) (*Uninstall, error) {
+	lock, err := LoadLock()
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := servercfg.Open()
+	if err != nil && !errors.Is(err, servercfg.ErrNotFound) {
+		return nil, err
+	}
+
+	result := &Uninstall{Name: name, Removed: []string{}}
+	entry := lock.Find(name)
+	for _, other := range lock.Plugins {
+		if other.Name != name && dependsOn(other.Name, name) {
+			result.Dependents = append(result.Dependents, other.Name)
+		}
+	}
+	if len(result.Dependents) > 0 && !force {
+		return result, fmt.Errorf("%s is required by %s (use --force to remove it anyway)", name, strings.Join(result.Dependents, ", "))
+	}
+
+	// Files another entry also lists stay, so removing one plugin cannot
+	// break another
+	paths := []string{
+		filepath.Join("plugins", name+".so"),
+		filepath.Join("plugins", name+".dll"),
+		filepath.Join(projectIncludeDir(), name+".inc"),
+	}
+	if entry != nil {
+		paths = entry.Files
+	}
+	shared := make(map[string]bool)
+	for _, other := range lock.Plugins {
+		if other.Name != name {
+			for _, f := range other.Files {
+				shared[f] = true
+			}
+		}
+	}
+	var remove []string
+	for _, path := range paths {
+		switch {
+		case shared[path]:
+			result.Kept = append(result.Kept, path)
+		case fileExists(path):
+			remove = append(remove, path)
+		}
+	}
+
+	// The names the server config may load it under: the plugin name and
+	// the name of every binary it placed
+	names := []string{name}
+	for _, path := range paths {
+		if isBinary(path) {
+			names = append(names, strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)))
+		}
+	}
+	loaded := false
+	if cfg != nil {
+		for _, n := range names {
+			loaded = cfg.HasPlugin(n) || loaded
+		}
+	}
+
+	if entry == nil && len(remove) == 0 && !loaded {
+		return nil, fmt.Errorf("plugin '%s' not found", name)
+	}
+
+	// Snapshot what is about to change
+	lockData, lockErr := os.ReadFile(LockFile)
+	var cfgData []byte
+	if loaded {
+		if cfgData, err = os.ReadFile(cfg.Path); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := os.MkdirAll(".fpawn", 0755); err != nil {
+		return nil, err
+	}
+	stage, err := os.MkdirTemp(".fpawn", "uninstall-")
+	if err != nil {
+		return nil, err
+	}
+	var moved []staged
+	rollback := func(cause error) (*Uninstall, error) {
+		var failed []string
+		for i := len(moved) - 1; i >= 0; i-- {
+			if err := os.Rename(moved[i].aside, moved[i].path); err != nil {
+				failed = append(failed, moved[i].path)
+			}
+		}
+		if lockErr == nil {
+			os.WriteFile(LockFile, lockData, 0644)
+		} else if os.IsNotExist(lockErr) {
+			os.Remove(LockFile)
+		}
+		if cfgData != nil {
+			os.WriteFile(cfg.Path, cfgData, 0644)
+		}
+		if len(failed) > 0 {
+			return nil, fmt.Errorf("%v; rollback could not restore %s (copies are in %s)", cause, strings.Join(failed, ", "), stage)
+		}
+		os.RemoveAll(stage)
+		return nil, fmt.Errorf("%v; nothing was changed", cause)
+	}
+
+	for i, path := range remove {
+		aside := filepath.Join(stage, fmt.Sprintf("%d-%s", i, filepath.Base(path)))
+		if err := os.Rename(path, aside); err != nil {
+			return rollback(fmt.Errorf("remove %s: %v", path, err))
+		}
+		moved = append(moved, staged{path, aside})
+	}
+
+	if lock.Remove(name) {
+		if err := lock.Save(); err != nil {
+			return rollback(fmt.Errorf("update %s: %v", LockFile, err))
+		}
+		result.Unlocked = true
+	}
+
+	if loaded {
+		for _, n := range names {
+			if _, err := cfg.RemovePlugin(n); err != nil {
+				return rollback(fmt.Errorf("update %s: %v", cfg.Path, err))
+			}
+		}
+		if err := cfg.Save(); err != nil {
+			return rollback(fmt.Errorf("update %s: %v", cfg.Path, err))
+		}
+		result.Config = cfg.Path
+	}
+
+	// Commit
+	os.RemoveAll(stage)
+	for _, m := range moved {
+		result.Removed = append(result.Removed, filepath.ToSlash(m.path))
+		pruneDirs(filepath.Dir(m.path))
+	}
+	return result, nil
+}
+
+// UninstallPlugin removes a plugin and prints what was done
+func UninstallPlugin(name string, force bool) (*Uninstall, error) {
+	fmt.Printf("\n %s Uninstalling: %s\n", core.Red("🗑️"), core.Bold(name))
+	fmt.Println(" ──────────────────────────────────────────────────")
+
+	result, err := RemovePlugin(name, force)
+	if err != nil {
+		fmt.Printf(" %s %v\n", core.Red("[Error]"), err)
+		return result, err
+	}
+
+	if len(result.Dependents) > 0 {
+		fmt.Printf(" %s Still required by: %s\n", core.Yellow("[Warn]"), strings.Join(result.Dependents, ", "))
+	}
+	for _, path := range result.Removed {
+		fmt.Printf(" %s Removed: %s\n", core.Green("[OK]"), path)
+	}
+	for _, path := range result.Kept {
+		fmt.Printf(" %s Kept: %s (also installed by another plugin)\n", core.Cyan("[Info]"), path)
+	}
+	if result.Unlocked {
+		fmt.Printf(" %s Removed from %s\n", core.Blue("[Lock]"), LockFile)
+	}
+	if result.Config != "" {
+		fmt.Printf(" %s Removed from %s\n", core.Blue("[Config]"), result.Config)
+	}
+
+	fmt.Printf(" %s %s uninstalled\n", core.Green("✓"), name)
+	return result, nil
+}
+
+// dependsOn reports whether the index lists dep among the dependencies of
+// plugin
+func dependsOn(plugin, dep string) bool {
+	p := GetPluginByName(plugin)
+	if p == nil {
+		return false
+	}
+	for _, d := range p.Deps {
+		if n, _, err := ParseDep(d); err == nil && n == dep {
+			return true
+		}
+	}
+	return false
+}
+
+// pruneDirs removes dir and its parents while they are empty, stopping at
+// the directories the project layout itself provides
+func pruneDirs(dir string) {
+	keep := map[string]bool{
+		".": true, "plugins": true, "components": true, "filterscripts": true,
+		"include": true, "pawno": true, "qawno": true, projectIncludeDir(): true,
+		filepath.Join("pawno", "include"): true, filepath.Join("qawno", "include"): true,
+	}
+	for !keep[dir] {
+		if os.Remove(dir) != nil {
+			return
+		}
+		dir = filepath.Dir(dir)
+	}
+}
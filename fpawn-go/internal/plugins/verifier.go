@@ -1,99 +1,286 @@
 package plugins
 
 import (
-	"crypto/md5"
+	"crypto/sha256"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
-
 	"strings"
 
 	"github.com/FerzDevZ/fpawn/internal/core"
 )
 
-// PluginInfo holds information about an installed plugin
+// Integrity states of an installed binary
+const (
+	IntegrityOK       = "ok"
+	IntegrityModified = "modified"
+	IntegrityUnknown  = "unknown"
+	IntegrityMissing  = "missing"
+)
+
+// PluginInfo is one server binary checked by ScanPlugins
 type PluginInfo struct {
-	Name     string
-	Path     string
-	Size     int64
-	Hash     string
-	Verified bool
+	Name     string `json:"name"`
+	Path     string `json:"path"`
+	Size     int64  `json:"size"`
+	SHA256   string `json:"sha256,omitempty"`
+	Expected string `json:"expected,omitempty"`
+	// Source is where Expected came from: "lock" or "index"
+	Source string `json:"source,omitempty"`
+	// Status is one of the Integrity states
+	Status string `json:"status"`
+	Arch   string `json:"arch,omitempty"`
+	// ArchError says why the server cannot load the binary
+	ArchError string `json:"arch_error,omitempty"`
+	Verified  bool   `json:"verified"`
 }
 
-// VerifyPlugins checks the integrity of installed plugins
-func VerifyPlugins() ([]PluginInfo, error) {
+// VerifyReport is the outcome of checking every installed binary
+type VerifyReport struct {
+	ServerArch string       `json:"server_arch"`
+	Plugins    []PluginInfo `json:"plugins"`
+	OK         int          `json:"ok"`
+	Modified   int          `json:"modified"`
+	Unknown    int          `json:"unknown"`
+	Missing    int          `json:"missing"`
+	WrongArch  int          `json:"wrong_arch"`
+}
+
+// Tampered reports whether a binary was modified, is missing or cannot be
+// loaded by the server. strict also counts binaries nothing vouches for.
+func (r *VerifyReport) Tampered(strict bool) bool {
+	return r.Modified+r.Missing+r.WrongArch > 0 || (strict && r.Unknown > 0)
+}
+
+// VerifyPlugins checks installed plugins and prints the result
+func VerifyPlugins() (*VerifyReport, error) {
 	fmt.Printf("\n %s %s\n", core.LBlue("🔍"), core.Bold("Plugin Integrity Verification"))
 	fmt.Println(" ──────────────────────────────────────────────────")
 
-	if _, err := os.Stat("plugins"); os.IsNotExist(err) {
-		return nil, fmt.Errorf("plugins directory not found")
+	report, err := ScanPlugins()
+	if err != nil {
+		fmt.Printf(" %s %v\n", core.Red("[Error]"), err)
+		return nil, err
 	}
 
-	var plugins []PluginInfo
+	PrintVerifyReport(report)
+	return report, nil
+}
 
-	err := filepath.Walk("plugins", func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return nil
-		}
-		if info.IsDir() {
-			return nil
+// ScanPlugins checks every plugin and component binary without printing
+// anything. Each one is hashed with SHA-256 and compared with the digest
+// recorded in fpawn.lock when it was installed, or failing that with the
+// digests the plugin index publishes; its header must also match the
+// architecture of the server. Binaries fpawn.lock lists but that are gone
+// are reported as missing.
+func ScanPlugins() (*VerifyReport, error) {
+	lock, err := LoadLock()
+	if err != nil {
+		return nil, err
+	}
+	owners := make(map[string]*LockedPlugin)
+	for _, entry := range lock.Plugins {
+		for _, f := range entry.Files {
+			owners[f] = entry
 		}
+	}
+
+	want, from := serverArch()
+	report := &VerifyReport{ServerArch: want.String(), Plugins: []PluginInfo{}}
+	if from != "" {
+		report.ServerArch += " (" + from + ")"
+	}
 
-		// Only check .so and .dll files
-		ext := filepath.Ext(path)
-		if ext != ".so" && ext != ".dll" && ext != ".dylib" {
+	found := false
+	for _, dir := range []string{"plugins", "components"} {
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			continue
+		}
+		found = true
+		err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+			if err != nil || info.IsDir() {
+				return nil
+			}
+			ext := strings.ToLower(filepath.Ext(path))
+			if ext != ".so" && ext != ".dll" {
+				return nil
+			}
+			report.add(checkBinary(filepath.ToSlash(path), info.Size(), owners, want))
 			return nil
+		})
+		if err != nil {
+			return nil, err
 		}
+	}
 
-		pluginInfo := PluginInfo{
-			Name: info.Name(),
-			Path: path,
-			Size: info.Size(),
+	for _, entry := range lock.Plugins {
+		for _, f := range entry.Files {
+			if isBinary(f) && !fileExists(f) {
+				found = true
+				report.add(PluginInfo{
+					Name:     entry.Name,
+					Path:     f,
+					Expected: entry.Hashes[f],
+					Source:   "lock",
+					Status:   IntegrityMissing,
+				})
+			}
 		}
+	}
 
-		// Calculate hash
-		hash, err := calculateHash(path)
-		if err != nil {
-			pluginInfo.Hash = "ERROR"
-			pluginInfo.Verified = false
-		} else {
-			pluginInfo.Hash = hash
-			pluginInfo.Verified = true
-		}
+	if !found {
+		return nil, fmt.Errorf("no plugins or components directory found")
+	}
+	return report, nil
+}
 
-		plugins = append(plugins, pluginInfo)
-		return nil
-	})
+// checkBinary hashes one binary and reads its header
+func checkBinary(path string, size int64, owners map[string]*LockedPlugin, want binArch) PluginInfo {
+	base := filepath.Base(path)
+	p := PluginInfo{
+		Name:   strings.TrimSuffix(base, filepath.Ext(base)),
+		Path:   path,
+		Size:   size,
+		Status: IntegrityUnknown,
+	}
 
+	sum, err := hashFile(path)
 	if err != nil {
-		return nil, err
+		p.ArchError = err.Error()
+		return p
+	}
+	p.SHA256 = sum
+
+	if entry := owners[path]; entry != nil {
+		p.Name = entry.Name
+		if h := entry.Hashes[path]; h != "" {
+			p.Expected, p.Source = h, "lock"
+		} else if h := publishedDigests(entry.Name, entry.Tag, base); len(h) == 1 {
+			p.Expected, p.Source = h[0], "index"
+		}
+	} else {
+		// Placed by hand, so the release is unknown: any digest the index
+		// publishes for the file vouches for it
+		for _, h := range publishedDigests(p.Name, "", base) {
+			if strings.EqualFold(h, sum) {
+				p.Expected, p.Source = h, "index"
+			}
+		}
+	}
+	switch {
+	case p.Expected == "":
+		p.Status = IntegrityUnknown
+	case strings.EqualFold(p.Expected, sum):
+		p.Status = IntegrityOK
+	default:
+		p.Status = IntegrityModified
 	}
 
-	// Display results
-	for _, p := range plugins {
-		status := core.Green("✓")
-		if !p.Verified {
-			status = core.Red("✗")
+	if a, err := readArch(path); err != nil {
+		p.ArchError = err.Error()
+	} else {
+		p.Arch = a.String()
+		p.ArchError = checkArch(path, a, want)
+	}
+	p.Verified = p.Status == IntegrityOK && p.ArchError == ""
+	return p
+}
+
+func (r *VerifyReport) add(p PluginInfo) {
+	switch p.Status {
+	case IntegrityOK:
+		r.OK++
+	case IntegrityModified:
+		r.Modified++
+	case IntegrityUnknown:
+		r.Unknown++
+	case IntegrityMissing:
+		r.Missing++
+	}
+	if p.ArchError != "" {
+		r.WrongArch++
+	}
+	r.Plugins = append(r.Plugins, p)
+}
+
+// publishedDigests returns the digests the plugin index lists for file of
+// plugin name at tag, or at every release when tag is empty
+func publishedDigests(name, tag, file string) []string {
+	plugin := GetPluginByName(name)
+	if plugin == nil {
+		return nil
+	}
+	var digests []string
+	for t, files := range plugin.Checksums {
+		if tag != "" && t != tag {
+			continue
+		}
+		if h := files[file]; h != "" {
+			digests = append(digests, h)
 		}
+	}
+	return digests
+}
+
+func isBinary(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return ext == ".so" || ext == ".dll"
+}
+
+// PrintVerifyReport renders plugin verification results for the terminal
+func PrintVerifyReport(report *VerifyReport) {
+	fmt.Printf(" %s Server: %s\n", core.Cyan("[Info]"), report.ServerArch)
+	fmt.Println()
 
-		fmt.Printf(" %s %-25s %s (%d KB)\n", status, p.Name, p.Hash[:8]+"...", p.Size/1024)
+	short := func(h string) string {
+		if len(h) > 12 {
+			return h[:12]
+		}
+		return h
+	}
+	for _, p := range report.Plugins {
+		switch p.Status {
+		case IntegrityOK:
+			status := core.Green("✓")
+			if p.ArchError != "" {
+				status = core.Red("✗")
+			}
+			fmt.Printf(" %s %-20s %s  %s (%s)\n", status, p.Name, p.Path, short(p.SHA256), p.Source)
+		case IntegrityModified:
+			fmt.Printf(" %s %-20s %s  %s\n", core.Red("✗"), p.Name, p.Path, core.Red("MODIFIED"))
+			fmt.Printf("     expected %s (%s), got %s\n", short(p.Expected), p.Source, short(p.SHA256))
+		case IntegrityMissing:
+			fmt.Printf(" %s %-20s %s  %s\n", core.Red("✗"), p.Name, p.Path, core.Red("MISSING"))
+		default:
+			fmt.Printf(" %s %-20s %s  %s\n", core.Yellow("?"), p.Name, p.Path, core.Yellow("unknown"))
+			if p.SHA256 != "" {
+				fmt.Printf("     %s, not recorded in %s or the plugin index\n", short(p.SHA256), LockFile)
+			}
+		}
+		if p.ArchError != "" {
+			fmt.Printf("     %s %s\n", core.Red("[Arch]"), p.ArchError)
+		}
 	}
 
 	fmt.Println(" ──────────────────────────────────────────────────")
-	fmt.Printf(" %s Verified: %d plugins\n", core.Green("✓"), len(plugins))
-
-	return plugins, nil
+	fmt.Printf(" %s %d verified  %s %d modified  %s %d missing  %s %d unknown  %s %d wrong architecture\n",
+		core.Green("✓"), report.OK, core.Red("✗"), report.Modified, core.Red("✗"), report.Missing,
+		core.Yellow("?"), report.Unknown, core.Red("✗"), report.WrongArch)
+	if report.Unknown > 0 {
+		fmt.Printf(" %s Reinstall unknown plugins with: fpawn install <name> to record them\n", core.Cyan("[Tip]"))
+	}
 }
 
-func calculateHash(path string) (string, error) {
+// hashFile returns the SHA-256 of path
+func hashFile(path string) (string, error) {
 	file, err := os.Open(path)
 	if err != nil {
 		return "", err
 	}
 	defer file.Close()
 
-	hash := md5.New()
+	hash := sha256.New()
 	if _, err := io.Copy(hash, file); err != nil {
 		return "", err
 	}
@@ -101,90 +288,57 @@ func calculateHash(path string) (string, error) {
 	return fmt.Sprintf("%x", hash.Sum(nil)), nil
 }
 
-// CheckDependencies checks if all plugin dependencies are satisfied
-func CheckDependencies() {
-	fmt.Printf("\n %s %s\n", core.LBlue("🔗"), core.Bold("Dependency Graph"))
+// CheckDependencies checks the plugins the server loads against the
+// natives target calls and prints the result
+func CheckDependencies(target string) (*DependencyReport, error) {
+	fmt.Printf("\n %s %s\n", core.LBlue("🔗"), core.Bold("Native Dependency Check"))
 	fmt.Println(" ──────────────────────────────────────────────────")
 
-	// Read server.cfg to find loaded plugins
-	data, err := os.ReadFile("server.cfg")
+	report, err := AnalyzeDependencies(target)
 	if err != nil {
-		fmt.Printf(" %s server.cfg not found\n", core.Red("[Error]"))
-		return
+		fmt.Printf(" %s %v\n", core.Red("[Error]"), err)
+		return nil, err
 	}
 
-	content := string(data)
-	installedPlugins := make(map[string]bool)
-
-	// Simple parsing
-	for _, plugin := range PluginDatabase {
-		if fileExists(filepath.Join("plugins", plugin.Name+".so")) ||
-			fileExists(filepath.Join("plugins", plugin.Name+".dll")) {
-			installedPlugins[plugin.Name] = true
+	fmt.Printf(" %s %s: %d files, %d natives declared\n", core.Cyan("[Info]"), report.Entry, report.Files, report.Natives)
+	fmt.Println()
+	for _, u := range report.Plugins {
+		where := u.Entry
+		if u.Component {
+			where = "component"
 		}
-	}
-
-	fmt.Printf(" %s Installed plugins: %d\n", core.Cyan("[Info]"), len(installedPlugins))
-
-	// Check for common dependencies
-	if installedPlugins["mysql"] {
-		if !installedPlugins["bcrypt"] && !installedPlugins["whirlpool"] {
-			fmt.Printf(" %s mysql installed but no password hashing plugin found\n", core.Yellow("[Suggest]"))
-			fmt.Printf("   Run: fpawn --install bcrypt\n")
+		switch u.Status {
+		case "ok":
+			fmt.Printf(" %s %-20s %-18s %d natives used\n", core.Green("✓"), u.Name, where, len(u.Used))
+		case "missing":
+			fmt.Printf(" %s %-20s %s %s\n", core.Red("✗"), u.Name, core.Red("MISSING"), u.Problem)
+			fmt.Printf("     %s\n", sample(u.Used, 5))
+			fmt.Printf("     load it with: fpawn cfg add-plugin %s\n", u.Name)
+		case "order":
+			fmt.Printf(" %s %-20s %s %s\n", core.Red("✗"), u.Name, core.Red("ORDER"), u.Problem)
+		case "unused":
+			fmt.Printf(" %s %-20s %s %s\n", core.Yellow("!"), u.Name, core.Yellow("UNUSED"), u.Problem)
 		}
 	}
-
-	if installedPlugins["crashdetect"] {
-		fmt.Printf(" %s crashdetect installed - debug symbols available\n", core.Green("[Good]"))
+	if len(report.Unresolved) > 0 {
+		fmt.Printf(" %s %d natives no known plugin provides: %s\n", core.Yellow("?"), len(report.Unresolved), sample(report.Unresolved, 8))
 	}
 
-	_ = content // Will be used in future for deeper analysis
+	fmt.Println(" ──────────────────────────────────────────────────")
+	fmt.Printf(" %s %d missing  %s %d out of order  %s %d unused (%s)\n",
+		core.Red("✗"), report.Missing, core.Red("✗"), report.Misordered, core.Yellow("!"), report.Unused, report.Config)
+	return report, nil
+}
+
+// sample joins the first n names and counts the rest
+func sample(names []string, n int) string {
+	if len(names) <= n {
+		return strings.Join(names, ", ")
+	}
+	return fmt.Sprintf("%s and %d more", strings.Join(names[:n], ", "), len(names)-n)
 }
 
 func fileExists(path string) bool {
 	_, err := os.Stat(path)
 	return err == nil
 }
-
-// UninstallPlugin removes a plugin
-func UninstallPlugin(name string) error {
-	fmt.Printf("\n %s Uninstalling: %s\n", core.Red("🗑️"), core.Bold(name))
-	fmt.Println(" ──────────────────────────────────────────────────")
-
-	// Remove plugin file
-	paths := []string{
-		filepath.Join("plugins", name+".so"),
-		filepath.Join("plugins", name+".dll"),
-		filepath.Join("include", name+".inc"),
-	}
-
-	removed := false
-	for _, path := range paths {
-		if fileExists(path) {
-			if err := os.Remove(path); err != nil {
-				fmt.Printf(" %s Failed to remove: %s\n", core.Red("[Error]"), path)
-			} else {
-				fmt.Printf(" %s Removed: %s\n", core.Green("[OK]"), path)
-				removed = true
-			}
-		}
-	}
-
-	if !removed {
-		return fmt.Errorf("plugin '%s' not found", name)
-	}
-
-	// Remove from server.cfg
-	cfgPath := "server.cfg"
-	if data, err := os.ReadFile(cfgPath); err == nil {
-		content := string(data)
-		// Simple removal (more sophisticated needed for production)
-		content = strings.Replace(content, " "+name, "", -1)
-		content = strings.Replace(content, name+" ", "", -1)
-		os.WriteFile(cfgPath, []byte(content), 0644)
-	}
-
-	fmt.Printf(" %s %s uninstalled\n", core.Green("✓"), name)
-	return nil
-}
-
@@ -0,0 +1,269 @@
+package plugins
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/FerzDevZ/fpawn/internal/core"
+)
+
+// RestoredPlugin is the outcome of restoring one fpawn.lock entry
+type RestoredPlugin struct {
+	Name string `json:"name"`
+	Tag  string `json:"tag,omitempty"`
+	// Status is "present", "restored" or "failed"
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// OutdatedPlugin compares a locked plugin with its newest release
+type OutdatedPlugin struct {
+	Name     string `json:"name"`
+	Locked   string `json:"locked"`
+	Latest   string `json:"latest"`
+	Outdated bool   `json:"outdated"`
+	// Source is set for plugins installed from a git repository, path or
+	// URL, which have no releases to compare with
+	Source string `json:"source,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// RestoreLocked installs exactly the plugin set recorded in fpawn.lock.
+// Entries whose files are all present are left alone; the others are
+// downloaded from their recorded URL and must match the recorded SHA-256.
+func RestoreLocked(c *Client) ([]RestoredPlugin, error) {
+	lock, err := LoadLock()
+	if err != nil {
+		return nil, err
+	}
+	if len(lock.Plugins) == 0 {
+		return nil, fmt.Errorf("%s records no plugins (install one with: fpawn install <name>)", LockFile)
+	}
+
+	var results []RestoredPlugin
+	for _, entry := range lock.Plugins {
+		r := RestoredPlugin{Name: entry.Name, Tag: entry.Tag, Status: "present"}
+		if !entry.Present() {
+			// Work on a copy so a failed download cannot rewrite the lock
+			restored := *entry
+			if err := fetchLocked(&restored, c); err != nil {
+				r.Status, r.Error = "failed", err.Error()
+			} else {
+				r.Status = "restored"
+			}
+		}
+		results = append(results, r)
+	}
+	return results, nil
+}
+
+// InstallLocked restores fpawn.lock and prints what was done
+func InstallLocked(c *Client) ([]RestoredPlugin, error) {
+	fmt.Printf("\n %s %s\n", core.LBlue("🔒"), core.Bold("Restoring plugins from "+LockFile))
+	fmt.Println(" ──────────────────────────────────────────────────")
+
+	results, err := RestoreLocked(c)
+	if err != nil {
+		return nil, err
+	}
+	return results, printRestored(results)
+}
+
+// printRestored lists the outcome of a restore and fails if any plugin
+// could not be installed
+func printRestored(results []RestoredPlugin) error {
+	failed := 0
+	for _, r := range results {
+		tag := r.Tag
+		if tag == "" {
+			tag = "(include)"
+		}
+		switch r.Status {
+		case "present":
+			fmt.Printf(" %s %-20s %s\n", core.Green("✓"), r.Name, tag)
+		case "restored":
+			fmt.Printf(" %s %-20s %s %s\n", core.Green("✓"), r.Name, tag, core.Cyan("restored"))
+		default:
+			fmt.Printf(" %s %-20s %s %s\n", core.Red("✗"), r.Name, tag, r.Error)
+			failed++
+		}
+	}
+
+	fmt.Println(" ──────────────────────────────────────────────────")
+	if failed > 0 {
+		return fmt.Errorf("%d plugin(s) could not be restored", failed)
+	}
+	fmt.Printf(" %s %d plugin(s) match %s\n", core.Green("✓"), len(results), LockFile)
+	return nil
+}
+
+// CheckOutdated asks the API for the newest release of every locked plugin
+func CheckOutdated(c *Client) ([]OutdatedPlugin, error) {
+	lock, err := LoadLock()
+	if err != nil {
+		return nil, err
+	}
+
+	var results []OutdatedPlugin
+	for _, entry := range lock.Plugins {
+		r := OutdatedPlugin{Name: entry.Name, Locked: entry.Tag}
+		if src := lockedSource(entry); src != nil {
+			r.Source = src.String()
+			results = append(results, r)
+			continue
+		}
+		if entry.Tag == "" {
+			// Include files from a branch have no release to compare with
+			results = append(results, r)
+			continue
+		}
+		release, err := c.LatestRelease(entry.Repo)
+		if err != nil {
+			r.Error = err.Error()
+		} else {
+			r.Latest = release.TagName
+			r.Outdated = release.TagName != entry.Tag
+		}
+		results = append(results, r)
+	}
+	return results, nil
+}
+
+// ShowOutdated prints the locked plugins that have a newer release
+func ShowOutdated(c *Client) ([]OutdatedPlugin, error) {
+	fmt.Printf("\n %s %s\n", core.LBlue("📦"), core.Bold("Plugin Updates"))
+	fmt.Println(" ──────────────────────────────────────────────────")
+
+	results, err := CheckOutdated(c)
+	if err != nil {
+		return nil, err
+	}
+
+	outdated := 0
+	for _, r := range results {
+		switch {
+		case r.Error != "":
+			fmt.Printf(" %s %-20s %s\n", core.Yellow("?"), r.Name, r.Error)
+		case r.Source != "":
+			fmt.Printf(" %s %-20s %s\n", core.Cyan("-"), r.Name, "from "+r.Source+" (run: fpawn upgrade "+r.Name+")")
+		case r.Locked == "":
+			fmt.Printf(" %s %-20s %s\n", core.Cyan("-"), r.Name, "include file, no releases")
+		case r.Outdated:
+			fmt.Printf(" %s %-20s %s → %s\n", core.Yellow("↑"), r.Name, r.Locked, core.Green(r.Latest))
+			outdated++
+		default:
+			fmt.Printf(" %s %-20s %s\n", core.Green("✓"), r.Name, r.Locked)
+		}
+	}
+
+	fmt.Println(" ──────────────────────────────────────────────────")
+	if outdated == 0 {
+		fmt.Printf(" %s Every locked plugin is on its latest release\n", core.Green("✓"))
+	} else {
+		fmt.Printf(" %s %d update(s) available (run: fpawn upgrade <name>)\n", core.Cyan("[Info]"), outdated)
+	}
+	return results, nil
+}
+
+// UpgradePlugin moves a locked plugin to tag, or to its latest release when
+// tag is empty, and removes files the old version placed that the new one
+// does not
+func UpgradePlugin(name, tag string, c *Client) error {
+	lock, err := LoadLock()
+	if err != nil {
+		return err
+	}
+	old := lock.Find(name)
+	if old == nil {
+		return fmt.Errorf("%s is not in %s (install it with: fpawn install %s)", name, LockFile, name)
+	}
+
+	fmt.Printf("\n %s Upgrading: %s\n", core.LBlue("📦"), core.Bold(name))
+	fmt.Println(" ──────────────────────────────────────────────────")
+
+	if src := lockedSource(old); src != nil {
+		return upgradeSource(lock, old, src, tag, c)
+	}
+	if tag == "" {
+		release, err := c.LatestRelease(old.Repo)
+		if err != nil {
+			return err
+		}
+		tag = release.TagName
+	}
+	if tag == old.Tag && old.Present() {
+		fmt.Printf(" %s %s is already at %s\n", core.Green("✓"), name, tag)
+		return nil
+	}
+
+	plugin := GetPluginByName(name)
+	if plugin == nil {
+		plugin = &Plugin{Name: name, URL: "https://github.com/" + old.Repo}
+	}
+	entry, err := installRelease(plugin, tag, c)
+	if err != nil {
+		return err
+	}
+
+	if err := replaceLocked(lock, old, entry); err != nil {
+		return err
+	}
+	from := old.Tag
+	if from == "" {
+		from = "(include)"
+	}
+	fmt.Printf(" %s %s %s → %s\n", core.Green("✓"), name, from, entry.Tag)
+	return nil
+}
+
+// upgradeSource reinstalls a plugin given by location: a git repository
+// moves to ref (or the newest commit of the ref it tracks), a local path is
+// copied again. A download URL names one file, so there is nothing to
+// upgrade to.
+func upgradeSource(lock *Lock, old *LockedPlugin, src *Source, ref string, c *Client) error {
+	switch src.Kind {
+	case SourceURL:
+		return fmt.Errorf("%s was downloaded from %s; install the new version with: fpawn install %s=<url>", old.Name, src.Location, old.Name)
+	case SourcePath:
+		if ref != "" {
+			return fmt.Errorf("%s was installed from %s, which has no versions", old.Name, src.Location)
+		}
+	case SourceGit:
+		if ref != "" {
+			src.Ref = ref
+		}
+	}
+
+	entry, err := installSource(src, c)
+	if err != nil {
+		return err
+	}
+	if err := replaceLocked(lock, old, entry); err != nil {
+		return err
+	}
+	if entry.URL == old.URL && entry.SHA256 == old.SHA256 {
+		fmt.Printf(" %s %s is already up to date with %s\n", core.Green("✓"), old.Name, src)
+	} else {
+		fmt.Printf(" %s %s reinstalled from %s\n", core.Green("✓"), old.Name, src)
+	}
+	return nil
+}
+
+// replaceLocked removes the files old placed that entry did not and puts
+// entry in the lock in its place
+func replaceLocked(lock *Lock, old, entry *LockedPlugin) error {
+	placed := make(map[string]bool)
+	for _, f := range entry.Files {
+		placed[f] = true
+	}
+	for _, f := range old.Files {
+		if !placed[f] {
+			if err := os.Remove(f); err == nil {
+				fmt.Printf(" %s Removed stale file: %s\n", core.Blue("[Clean]"), f)
+			}
+		}
+	}
+
+	lock.Put(entry)
+	return lock.Save()
+}
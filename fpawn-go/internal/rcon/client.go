@@ -0,0 +1,204 @@
+package rcon
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"time"
+)
+
+// ErrBadPassword is returned when the server rejects the RCON password
+var ErrBadPassword = errors.New("invalid RCON password")
+
+// Info is the answer to the 'i' query
+type Info struct {
+	Password   bool   `json:"password"`
+	Players    int    `json:"players"`
+	MaxPlayers int    `json:"max_players"`
+	Hostname   string `json:"hostname"`
+	Gamemode   string `json:"gamemode"`
+	Language   string `json:"language"`
+}
+
+// Rule is one server rule from the 'r' query (version, weather, worldtime...)
+type Rule struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Player is one entry of the 'c' and 'd' queries. ID and Ping are only
+// filled in by the detailed query.
+type Player struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	Score int32  `json:"score"`
+	Ping  int    `json:"ping"`
+}
+
+// Client talks to one server over UDP. It is not safe for concurrent use.
+type Client struct {
+	Addr     *net.UDPAddr
+	Password string
+	// Timeout bounds how long a query waits for its answer
+	Timeout time.Duration
+	// Idle is how long an RCON command waits for further output lines
+	Idle time.Duration
+
+	conn *net.UDPConn
+}
+
+// Dial resolves host:port and opens a UDP socket to it. A missing port
+// defaults to 7777.
+func Dial(address, password string) (*Client, error) {
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		address = net.JoinHostPort(address, "7777")
+	}
+	addr, err := net.ResolveUDPAddr("udp4", address)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := net.DialUDP("udp4", nil, addr)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{
+		Addr:     addr,
+		Password: password,
+		Timeout:  2 * time.Second,
+		Idle:     300 * time.Millisecond,
+		conn:     conn,
+	}, nil
+}
+
+// Close releases the socket
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// exchange sends a request and returns the first well-formed answer to op
+func (c *Client) exchange(req []byte, op byte) ([]byte, error) {
+	if _, err := c.conn.Write(req); err != nil {
+		return nil, err
+	}
+	c.conn.SetReadDeadline(time.Now().Add(c.Timeout))
+	buf := make([]byte, 65535)
+	for {
+		n, err := c.conn.Read(buf)
+		if err != nil {
+			if errors.Is(err, os.ErrDeadlineExceeded) {
+				return nil, fmt.Errorf("no response from %s", c.Addr)
+			}
+			return nil, err
+		}
+		// Stray answers to earlier requests are skipped
+		if checkHeader(buf[:n], op) == nil {
+			return buf[headerLen:n], nil
+		}
+	}
+}
+
+// Info runs the 'i' query
+func (c *Client) Info() (*Info, error) {
+	p, err := c.exchange(header(c.Addr, OpInfo), OpInfo)
+	if err != nil {
+		return nil, err
+	}
+	r := &reader{p: p}
+	info := &Info{
+		Password:   r.u8() != 0,
+		Players:    r.u16(),
+		MaxPlayers: r.u16(),
+		Hostname:   r.str(4),
+		Gamemode:   r.str(4),
+		Language:   r.str(4),
+	}
+	return info, r.err
+}
+
+// Rules runs the 'r' query
+func (c *Client) Rules() ([]Rule, error) {
+	p, err := c.exchange(header(c.Addr, OpRules), OpRules)
+	if err != nil {
+		return nil, err
+	}
+	r := &reader{p: p}
+	rules := make([]Rule, r.u16())
+	for i := range rules {
+		rules[i] = Rule{Name: r.str(1), Value: r.str(1)}
+	}
+	return rules, r.err
+}
+
+// Clients runs the 'c' query: names and scores
+func (c *Client) Clients() ([]Player, error) {
+	p, err := c.exchange(header(c.Addr, OpClients), OpClients)
+	if err != nil {
+		return nil, err
+	}
+	r := &reader{p: p}
+	players := make([]Player, r.u16())
+	for i := range players {
+		players[i] = Player{Name: r.str(1), Score: int32(r.u32())}
+	}
+	return players, r.err
+}
+
+// Detail runs the 'd' query: ids, names, scores and pings
+func (c *Client) Detail() ([]Player, error) {
+	p, err := c.exchange(header(c.Addr, OpDetail), OpDetail)
+	if err != nil {
+		return nil, err
+	}
+	r := &reader{p: p}
+	players := make([]Player, r.u16())
+	for i := range players {
+		players[i] = Player{ID: r.u8(), Name: r.str(1), Score: int32(r.u32()), Ping: int(r.u32())}
+	}
+	return players, r.err
+}
+
+// Command sends an RCON command and collects the lines the server echoes
+// back. The server sends one packet per line with no terminator, so output
+// is read until nothing arrives for Idle. Commands such as gmx may answer
+// with nothing at all.
+func (c *Client) Command(cmd string) ([]string, error) {
+	req := header(c.Addr, OpRCON)
+	req = appendStr(req, c.Password)
+	req = appendStr(req, cmd)
+	if _, err := c.conn.Write(req); err != nil {
+		return nil, err
+	}
+
+	var lines []string
+	buf := make([]byte, 65535)
+	deadline := time.Now().Add(c.Timeout)
+	for {
+		wait := time.Now().Add(c.Idle)
+		if len(lines) == 0 {
+			// The first line may take longer than the gap between lines
+			wait = deadline
+		}
+		c.conn.SetReadDeadline(wait)
+		n, err := c.conn.Read(buf)
+		if err != nil {
+			if errors.Is(err, os.ErrDeadlineExceeded) {
+				return lines, nil
+			}
+			return lines, err
+		}
+		if checkHeader(buf[:n], OpRCON) != nil {
+			continue
+		}
+		r := &reader{p: buf[headerLen:n]}
+		line := r.str(2)
+		if r.err != nil {
+			return lines, r.err
+		}
+		if strings.HasPrefix(line, "Invalid RCON password") {
+			return nil, ErrBadPassword
+		}
+		lines = append(lines, line)
+	}
+}
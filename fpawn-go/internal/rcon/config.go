@@ -0,0 +1,46 @@
+package rcon
+
+import (
+	"net"
+	"strconv"
+
+	"github.com/FerzDevZ/fpawn/internal/manifest"
+	"github.com/FerzDevZ/fpawn/internal/servercfg"
+)
+
+// Target is where RCON commands for the current project go
+type Target struct {
+	Address  string
+	Password string
+}
+
+// Local works out the address and password of the server in the working
+// directory from the manifest runtime, then config.json (open.mp) or
+// server.cfg (SA-MP). It always answers; missing values fall back to
+// 127.0.0.1:7777 and an empty password.
+func Local() Target {
+	port, password := 0, ""
+	set := func(p int, pw string) {
+		if port == 0 {
+			port = p
+		}
+		if password == "" {
+			password = pw
+		}
+	}
+
+	if m := manifest.Current(); m != nil {
+		if rt := m.MainRuntime(); rt != nil {
+			set(rt.Port, rt.RCONPassword)
+		}
+	}
+	if cfg, err := servercfg.Open(); err == nil {
+		settings := cfg.Settings()
+		set(settings.Port, settings.RCONPassword)
+	}
+
+	if port == 0 {
+		port = 7777
+	}
+	return Target{Address: net.JoinHostPort("127.0.0.1", strconv.Itoa(port)), Password: password}
+}
@@ -0,0 +1,151 @@
+package rcon
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/FerzDevZ/fpawn/internal/core"
+)
+
+// QueryResult bundles every query opcode's answer for one server
+type QueryResult struct {
+	Address string   `json:"address"`
+	Info    *Info    `json:"info"`
+	Rules   []Rule   `json:"rules"`
+	Players []Player `json:"players"`
+}
+
+// Query asks a server for its info, rules and player list. The detailed
+// player list is tried first; servers with too many players to list only
+// answer the plain one, and servers above 100 players answer neither.
+func Query(address string) (*QueryResult, error) {
+	c, err := Dial(address, "")
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	info, err := c.Info()
+	if err != nil {
+		return nil, err
+	}
+	result := &QueryResult{Address: c.Addr.String(), Info: info}
+	if result.Rules, err = c.Rules(); err != nil {
+		return nil, err
+	}
+	if info.Players > 0 {
+		if result.Players, err = c.Detail(); err != nil {
+			result.Players, _ = c.Clients()
+		}
+	}
+	return result, nil
+}
+
+// Send runs one RCON command against t and returns its output
+func Send(t Target, cmd string) ([]string, error) {
+	c, err := Dial(t.Address, t.Password)
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+	return c.Command(cmd)
+}
+
+// SendCommand runs one RCON command and prints what the server answers
+func SendCommand(t Target, cmd string) error {
+	if t.Password == "" {
+		return fmt.Errorf("no RCON password (set rcon_password in server.cfg or pass --password)")
+	}
+	lines, err := Send(t, cmd)
+	if err != nil {
+		return err
+	}
+	for _, line := range lines {
+		fmt.Println(line)
+	}
+	if len(lines) == 0 {
+		fmt.Printf(" %s Sent %q to %s\n", core.Green("✓"), cmd, t.Address)
+	}
+	return nil
+}
+
+// Interactive reads commands from stdin and sends each one until "exit"
+func Interactive(t Target) error {
+	if t.Password == "" {
+		return fmt.Errorf("no RCON password (set rcon_password in server.cfg or pass --password)")
+	}
+	c, err := Dial(t.Address, t.Password)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	fmt.Printf("\n %s %s\n", core.LBlue("🎛"), core.Bold("RCON Console"))
+	fmt.Println(" ──────────────────────────────────────────────────")
+	fmt.Printf(" %s %s\n", core.Bold("Server:"), c.Addr)
+	fmt.Printf(" %s Type a command (cmdlist, gmx, reloadfs <name>...), \"exit\" to leave\n\n", core.Cyan("[Info]"))
+
+	scanner := bufio.NewScanner(os.Stdin)
+	for {
+		fmt.Print(core.Cyan("rcon> "))
+		if !scanner.Scan() {
+			fmt.Println()
+			return scanner.Err()
+		}
+		cmd := strings.TrimSpace(scanner.Text())
+		switch cmd {
+		case "":
+			continue
+		case "exit", "quit":
+			return nil
+		}
+
+		lines, err := c.Command(cmd)
+		if err == ErrBadPassword {
+			return err
+		}
+		if err != nil {
+			fmt.Printf(" %s %v\n", core.Red("[Error]"), err)
+			continue
+		}
+		for _, line := range lines {
+			fmt.Println(" " + line)
+		}
+	}
+}
+
+// ShowQuery queries a server and prints the result
+func ShowQuery(address string) error {
+	result, err := Query(address)
+	if err != nil {
+		return err
+	}
+	info := result.Info
+
+	fmt.Printf("\n %s %s\n", core.LBlue("📡"), core.Bold(info.Hostname))
+	fmt.Println(" ──────────────────────────────────────────────────")
+	fmt.Printf(" %s %s\n", core.Bold("Address:"), result.Address)
+	fmt.Printf(" %s %s\n", core.Bold("Gamemode:"), info.Gamemode)
+	fmt.Printf(" %s %s\n", core.Bold("Language:"), info.Language)
+	fmt.Printf(" %s %d/%d\n", core.Bold("Players:"), info.Players, info.MaxPlayers)
+	if info.Password {
+		fmt.Printf(" %s %s\n", core.Bold("Password:"), core.Yellow("required"))
+	}
+
+	if len(result.Rules) > 0 {
+		fmt.Printf("\n %s\n", core.Bold("Rules:"))
+		for _, rule := range result.Rules {
+			fmt.Printf("   %-12s %s\n", rule.Name, rule.Value)
+		}
+	}
+	if len(result.Players) > 0 {
+		fmt.Printf("\n %s\n", core.Bold("Online:"))
+		for _, p := range result.Players {
+			fmt.Printf("   [%3d] %-24s score %-6d ping %d\n", p.ID, p.Name, p.Score, p.Ping)
+		}
+	}
+	fmt.Println()
+	return nil
+}
@@ -0,0 +1,104 @@
+package rcon
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+)
+
+// Opcodes of the SA-MP/open.mp query protocol
+const (
+	OpInfo    byte = 'i'
+	OpRules   byte = 'r'
+	OpClients byte = 'c'
+	OpDetail  byte = 'd'
+	OpPing    byte = 'p'
+	OpRCON    byte = 'x'
+)
+
+// headerLen is "SAMP", the server's IPv4 address, its port and the opcode
+const headerLen = 11
+
+var errShortPacket = errors.New("truncated response")
+
+// header builds the 11-byte prefix every request and response carries
+func header(addr *net.UDPAddr, op byte) []byte {
+	b := make([]byte, headerLen, 64)
+	copy(b, "SAMP")
+	copy(b[4:8], addr.IP.To4())
+	binary.LittleEndian.PutUint16(b[8:10], uint16(addr.Port))
+	b[10] = op
+	return b
+}
+
+// checkHeader verifies a response is a SAMP packet answering op
+func checkHeader(p []byte, op byte) error {
+	if len(p) < headerLen || string(p[:4]) != "SAMP" {
+		return fmt.Errorf("not a SA-MP response")
+	}
+	if p[10] != op {
+		return fmt.Errorf("unexpected opcode %q in response to %q", p[10], op)
+	}
+	return nil
+}
+
+// reader decodes the little-endian fields that follow the header
+type reader struct {
+	p   []byte
+	err error
+}
+
+func (r *reader) take(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || len(r.p) < n {
+		r.err = errShortPacket
+		return nil
+	}
+	b := r.p[:n]
+	r.p = r.p[n:]
+	return b
+}
+
+func (r *reader) u8() int {
+	if b := r.take(1); b != nil {
+		return int(b[0])
+	}
+	return 0
+}
+
+func (r *reader) u16() int {
+	if b := r.take(2); b != nil {
+		return int(binary.LittleEndian.Uint16(b))
+	}
+	return 0
+}
+
+func (r *reader) u32() uint32 {
+	if b := r.take(4); b != nil {
+		return binary.LittleEndian.Uint32(b)
+	}
+	return 0
+}
+
+// str reads a string prefixed by a length of the given width in bytes
+func (r *reader) str(width int) string {
+	var n int
+	switch width {
+	case 1:
+		n = r.u8()
+	case 2:
+		n = r.u16()
+	default:
+		n = int(r.u32())
+	}
+	return string(r.take(n))
+}
+
+// appendStr writes s prefixed by its uint16 length, as RCON requests expect
+func appendStr(b []byte, s string) []byte {
+	b = binary.LittleEndian.AppendUint16(b, uint16(len(s)))
+	return append(b, s...)
+}
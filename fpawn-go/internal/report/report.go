@@ -0,0 +1,92 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Format selects how command results are rendered
+type Format string
+
+const (
+	FormatText  Format = "text"
+	FormatJSON  Format = "json"
+	FormatSARIF Format = "sarif"
+)
+
+// Current is the output format selected on the command line
+var Current = FormatText
+
+// ParseFormat validates a --format value
+func ParseFormat(value string) (Format, error) {
+	switch Format(strings.ToLower(value)) {
+	case FormatText:
+		return FormatText, nil
+	case FormatJSON:
+		return FormatJSON, nil
+	case FormatSARIF:
+		return FormatSARIF, nil
+	}
+	return "", fmt.Errorf("unknown output format '%s' (expected text, json or sarif)", value)
+}
+
+// Machine reports whether output must be a single structured document
+func Machine() bool {
+	return Current != FormatText
+}
+
+// Finding is a single source location reported by an analysis or build
+type Finding struct {
+	RuleID    string
+	Level     string // "error", "warning", "note"
+	Message   string
+	File      string
+	StartLine int
+	EndLine   int
+}
+
+// FindingSource is implemented by results that can be expressed as SARIF
+type FindingSource interface {
+	Findings() []Finding
+}
+
+// ErrorDocument is emitted in machine mode when a command fails outright
+type ErrorDocument struct {
+	Command string `json:"command"`
+	Error   string `json:"error"`
+}
+
+// Emit writes v to stdout in the current machine format
+func Emit(command string, v interface{}) error {
+	return Write(os.Stdout, Current, command, v)
+}
+
+// Write renders v to w in the given machine format
+func Write(w io.Writer, format Format, command string, v interface{}) error {
+	if format == FormatSARIF {
+		src, ok := v.(FindingSource)
+		if !ok {
+			return fmt.Errorf("command '%s' does not support --format=sarif", command)
+		}
+		v = toSARIF(src.Findings())
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// Fail emits an error document and exits with the given code
+func Fail(command string, err error, code int) {
+	if Machine() {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(ErrorDocument{Command: command, Error: err.Error()})
+	} else {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	}
+	os.Exit(code)
+}
@@ -0,0 +1,137 @@
+package server
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	// maxLogSize is the size at which server.log is rotated
+	maxLogSize = 5 << 20
+	// keepLogs is how many rotated logs (server.log.1 ... .N) are kept
+	keepLogs = 3
+)
+
+// rotatingLog is an io.Writer that rolls its file over once it grows past
+// maxLogSize. The server's stdout and stderr share one instance.
+type rotatingLog struct {
+	mu   sync.Mutex
+	path string
+	f    *os.File
+	size int64
+}
+
+func openLog(path string) (*rotatingLog, error) {
+	l := &rotatingLog{path: path}
+	if err := l.open(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+func (l *rotatingLog) open() error {
+	f, err := os.OpenFile(l.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	l.f, l.size = f, info.Size()
+	return nil
+}
+
+func (l *rotatingLog) rotate() error {
+	l.f.Close()
+	for i := keepLogs - 1; i >= 1; i-- {
+		os.Rename(fmt.Sprintf("%s.%d", l.path, i), fmt.Sprintf("%s.%d", l.path, i+1))
+	}
+	os.Rename(l.path, l.path+".1")
+	return l.open()
+}
+
+func (l *rotatingLog) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.size > 0 && l.size+int64(len(p)) > maxLogSize {
+		if err := l.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := l.f.Write(p)
+	l.size += int64(n)
+	return n, err
+}
+
+// Event writes a timestamped supervisor line between the server's output
+func (l *rotatingLog) Event(format string, args ...interface{}) {
+	fmt.Fprintf(l, "[fpawn %s] %s\n", time.Now().Format("2006-01-02 15:04:05"), fmt.Sprintf(format, args...))
+}
+
+func (l *rotatingLog) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.f.Close()
+}
+
+// Tail returns the last n lines of the current server log
+func Tail(n int) ([]string, error) {
+	f, err := os.Open(logFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+		if len(lines) > n {
+			lines = lines[1:]
+		}
+	}
+	return lines, scanner.Err()
+}
+
+// Follow copies everything appended to the server log to w until stop is
+// closed, starting over from the top when the log is rotated
+func Follow(w io.Writer, stop <-chan struct{}) error {
+	f, err := os.Open(logFile)
+	if err != nil {
+		return err
+	}
+	defer func() { f.Close() }()
+	if _, err := f.Seek(0, io.SeekEnd); err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(250 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		if _, err := io.Copy(w, f); err != nil {
+			return err
+		}
+		select {
+		case <-stop:
+			return nil
+		case <-ticker.C:
+		}
+		cur, err1 := f.Stat()
+		next, err2 := os.Stat(logFile)
+		if err1 == nil && err2 == nil && !os.SameFile(cur, next) {
+			// Drain what was written before the rotation, then switch files
+			io.Copy(w, f)
+			f.Close()
+			if f, err = os.Open(logFile); err != nil {
+				return err
+			}
+		}
+	}
+}
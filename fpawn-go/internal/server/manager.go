@@ -0,0 +1,100 @@
+package server
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/FerzDevZ/fpawn/internal/core"
+)
+
+// StartServer starts the supervised server and prints where it is running
+func StartServer() error {
+	st, err := Start()
+	if err != nil {
+		return err
+	}
+	fmt.Printf(" %s Started %s (pid %d, supervisor %d)\n", core.Green("[Server]"), st.State.Binary, st.State.PID, st.State.SupervisorPID)
+	fmt.Printf(" %s Output is captured to %s (fpawn server logs)\n", core.Cyan("[Log]"), LogPath())
+	return nil
+}
+
+// StopServer stops the supervised server
+func StopServer() error {
+	if err := Stop(); err != nil {
+		return err
+	}
+	fmt.Printf(" %s Server stopped\n", core.Green("[Server]"))
+	return nil
+}
+
+// RestartServer restarts the supervised server, starting it if it was down
+func RestartServer() error {
+	st, err := Restart()
+	if err != nil {
+		return err
+	}
+	fmt.Printf(" %s Restarted %s (pid %d)\n", core.Green("[Server]"), st.State.Binary, st.State.PID)
+	return nil
+}
+
+// ShowStatus prints the state of the supervisor and the server
+func ShowStatus() *Status {
+	st := Inspect()
+
+	fmt.Printf("\n %s %s\n", core.LBlue("🖥"), core.Bold("Server Status"))
+	fmt.Println(" ──────────────────────────────────────────────────")
+	switch {
+	case st.Running:
+		fmt.Printf(" %s %s (pid %d)\n", core.Bold("Server:"), core.Green("RUNNING"), st.State.PID)
+	case st.Supervised:
+		fmt.Printf(" %s %s\n", core.Bold("Server:"), core.Yellow("RESTARTING"))
+	default:
+		fmt.Printf(" %s %s\n", core.Bold("Server:"), core.Red("STOPPED"))
+	}
+	if st.State != nil && st.Supervised {
+		fmt.Printf(" %s %s\n", core.Bold("Binary:"), st.State.Binary)
+		fmt.Printf(" %s pid %d\n", core.Bold("Supervisor:"), st.State.SupervisorPID)
+		if st.Running {
+			fmt.Printf(" %s %s\n", core.Bold("Uptime:"), time.Since(st.State.StartedAt).Round(time.Second))
+		}
+		fmt.Printf(" %s %d\n", core.Bold("Restarts:"), st.State.Restarts)
+		if st.State.LastExit != "" {
+			fmt.Printf(" %s %s\n", core.Bold("Last exit:"), st.State.LastExit)
+		}
+	}
+	fmt.Printf(" %s %s\n\n", core.Bold("Log:"), st.Log)
+	return st
+}
+
+// ShowLogs prints the last lines of the server log and, with follow, keeps
+// printing new output until Ctrl+C
+func ShowLogs(lines int, follow bool) error {
+	if lines <= 0 {
+		lines = 50
+	}
+	tail, err := Tail(lines)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("no server log yet (%s)", LogPath())
+		}
+		return err
+	}
+	for _, line := range tail {
+		fmt.Println(line)
+	}
+	if !follow {
+		return nil
+	}
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	defer signal.Stop(interrupt)
+	stop := make(chan struct{})
+	go func() {
+		<-interrupt
+		close(stop)
+	}()
+	return Follow(os.Stdout, stop)
+}
@@ -0,0 +1,59 @@
+//go:build !windows
+
+package server
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"syscall"
+)
+
+// detach puts the supervisor in its own session so it outlives the shell
+// that ran "fpawn server start"
+func detach(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+}
+
+func alive(pid int) bool {
+	err := syscall.Kill(pid, 0)
+	return err == nil || err == syscall.EPERM
+}
+
+func terminate(pid int) {
+	syscall.Kill(pid, syscall.SIGTERM)
+}
+
+func kill(pid int) {
+	syscall.Kill(pid, syscall.SIGKILL)
+}
+
+// cmdline returns the argv of pid, or nil where /proc is unavailable
+func cmdline(pid int) [][]byte {
+	data, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "cmdline"))
+	if err != nil {
+		return nil
+	}
+	return bytes.Split(bytes.TrimRight(data, "\x00"), []byte{0})
+}
+
+// isSupervisor guards against a recycled PID: it checks that pid is still
+// an fpawn supervisor before it is signalled
+func isSupervisor(pid int) bool {
+	args := cmdline(pid)
+	if args == nil {
+		return true
+	}
+	return len(args) >= 3 && string(args[2]) == SuperviseCommand
+}
+
+// runs reports whether pid is executing binary
+func runs(pid int, binary string) bool {
+	args := cmdline(pid)
+	if args == nil {
+		return true
+	}
+	return len(args) > 0 && filepath.Base(string(args[0])) == filepath.Base(binary)
+}
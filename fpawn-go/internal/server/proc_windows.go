@@ -0,0 +1,42 @@
+//go:build windows
+
+package server
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+const createNewProcessGroup = 0x00000200
+
+// detach starts the supervisor in its own process group so it outlives the
+// console that ran "fpawn server start"
+func detach(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{CreationFlags: createNewProcessGroup}
+}
+
+func alive(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	p.Release()
+	return true
+}
+
+// terminate has no graceful equivalent on Windows
+func terminate(pid int) {
+	kill(pid)
+}
+
+func kill(pid int) {
+	if p, err := os.FindProcess(pid); err == nil {
+		p.Kill()
+		p.Release()
+	}
+}
+
+func isSupervisor(pid int) bool { return true }
+
+func runs(pid int, binary string) bool { return true }
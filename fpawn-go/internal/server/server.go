@@ -0,0 +1,207 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	stateDir  = ".fpawn"
+	pidFile   = filepath.Join(stateDir, "server.pid")
+	stateFile = filepath.Join(stateDir, "server.json")
+	logFile   = filepath.Join(stateDir, "server.log")
+)
+
+// SuperviseCommand is the hidden "fpawn server" subcommand the detached
+// supervisor runs as
+const SuperviseCommand = "__supervise"
+
+// Binaries lists the server executables looked for in the project root,
+// in order of preference
+var Binaries = []string{"./omp-server", "./samp03svr"}
+
+// State is what the supervisor records in .fpawn/server.json
+type State struct {
+	Binary        string    `json:"binary"`
+	SupervisorPID int       `json:"supervisor_pid"`
+	PID           int       `json:"pid"`
+	StartedAt     time.Time `json:"started_at"`
+	Restarts      int       `json:"restarts"`
+	LastExit      string    `json:"last_exit,omitempty"`
+}
+
+// Status describes the managed server as seen from outside the supervisor
+type Status struct {
+	Running    bool   `json:"running"`
+	Supervised bool   `json:"supervised"`
+	State      *State `json:"state,omitempty"`
+	Log        string `json:"log"`
+}
+
+// Binary returns the server executable of the current project
+func Binary() (string, error) {
+	for _, b := range Binaries {
+		if _, err := os.Stat(b); err == nil {
+			return b, nil
+		}
+	}
+	return "", fmt.Errorf("server binary not found (omp-server or samp03svr)")
+}
+
+// LogPath returns the file the server's stdout and stderr are captured to
+func LogPath() string {
+	return logFile
+}
+
+// Inspect reports whether the supervisor and the server it manages are alive
+func Inspect() *Status {
+	st := &Status{Log: logFile}
+	state := readState()
+	if state == nil {
+		if pid := readPID(); pid > 0 {
+			state = &State{PID: pid}
+		}
+	}
+	st.State = state
+	if state == nil {
+		return st
+	}
+	st.Supervised = state.SupervisorPID > 0 && alive(state.SupervisorPID) && isSupervisor(state.SupervisorPID)
+	st.Running = state.PID > 0 && alive(state.PID)
+	return st
+}
+
+// Start launches a detached supervisor that runs the server and restarts it
+// when it crashes. It waits until the server process is up.
+func Start() (*Status, error) {
+	if st := Inspect(); st.Supervised || st.Running {
+		return st, fmt.Errorf("server is already running (pid %d)", st.State.PID)
+	}
+	binary, err := Binary()
+	if err != nil {
+		return nil, err
+	}
+	self, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(stateDir, 0755); err != nil {
+		return nil, err
+	}
+	clearState()
+
+	cmd := exec.Command(self, "server", SuperviseCommand, binary)
+	cmd.Dir = "."
+	detach(cmd)
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	supervisor := cmd.Process.Pid
+	cmd.Process.Release()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if state := readState(); state != nil && state.SupervisorPID == supervisor && state.PID > 0 {
+			return Inspect(), nil
+		}
+		if !alive(supervisor) {
+			return nil, fmt.Errorf("server exited during startup, see %s", logFile)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	return Inspect(), fmt.Errorf("server did not start within 5s, see %s", logFile)
+}
+
+// Stop terminates the supervisor and the server. Only processes fpawn
+// started are signalled; nothing is killed by name.
+func Stop() error {
+	st := Inspect()
+	if !st.Supervised && !st.Running {
+		clearState()
+		return fmt.Errorf("server is not running")
+	}
+
+	if st.Supervised {
+		terminate(st.State.SupervisorPID)
+		if !waitExit(st.State.SupervisorPID, stopTimeout+2*time.Second) {
+			kill(st.State.SupervisorPID)
+		}
+	}
+	// An orphaned server is only touched if it is still the binary we ran
+	if pid := st.State.PID; pid > 0 && alive(pid) && (st.State.Binary == "" || runs(pid, st.State.Binary)) {
+		terminate(pid)
+		if !waitExit(pid, stopTimeout) {
+			kill(pid)
+		}
+	}
+	clearState()
+	return nil
+}
+
+// Restart stops the server if it is running and starts it again
+func Restart() (*Status, error) {
+	if st := Inspect(); st.Supervised || st.Running {
+		if err := Stop(); err != nil {
+			return nil, err
+		}
+	}
+	return Start()
+}
+
+func waitExit(pid int, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if !alive(pid) {
+			return true
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	return !alive(pid)
+}
+
+func readState() *State {
+	data, err := os.ReadFile(stateFile)
+	if err != nil {
+		return nil
+	}
+	var state State
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil
+	}
+	return &state
+}
+
+func writeState(state *State) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := stateFile + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, stateFile); err != nil {
+		return err
+	}
+	return os.WriteFile(pidFile, []byte(strconv.Itoa(state.PID)+"\n"), 0644)
+}
+
+func readPID() int {
+	data, err := os.ReadFile(pidFile)
+	if err != nil {
+		return 0
+	}
+	pid, _ := strconv.Atoi(strings.TrimSpace(string(data)))
+	return pid
+}
+
+func clearState() {
+	os.Remove(stateFile)
+	os.Remove(pidFile)
+}
@@ -0,0 +1,111 @@
+package server
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+const (
+	// stopTimeout is how long the server gets to shut down before it is killed
+	stopTimeout = 10 * time.Second
+	// stableAfter is how long a run must last for its crash to reset the backoff
+	stableAfter = time.Minute
+	minBackoff  = time.Second
+	maxBackoff  = 30 * time.Second
+	// maxCrashes consecutive short-lived runs make the supervisor give up
+	maxCrashes = 10
+)
+
+// Supervise runs binary in the foreground, captures its output to the
+// rotating server log and restarts it with exponential backoff whenever it
+// exits. It returns once it receives SIGINT/SIGTERM and the server is down.
+func Supervise(binary string) error {
+	logw, err := openLog(logFile)
+	if err != nil {
+		return err
+	}
+	defer logw.Close()
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(stop)
+	defer clearState()
+
+	state := &State{Binary: binary, SupervisorPID: os.Getpid()}
+	backoff := minBackoff
+	crashes := 0
+
+	for {
+		cmd := exec.Command(binary)
+		cmd.Dir = "."
+		cmd.Stdout = logw
+		cmd.Stderr = logw
+		cmd.Env = append(os.Environ(), "LD_LIBRARY_PATH=.:"+os.Getenv("LD_LIBRARY_PATH"))
+
+		if err := cmd.Start(); err != nil {
+			logw.Event("failed to start %s: %v", binary, err)
+			return err
+		}
+		state.PID = cmd.Process.Pid
+		state.StartedAt = time.Now()
+		if err := writeState(state); err != nil {
+			logw.Event("cannot record state: %v", err)
+		}
+		logw.Event("started %s (pid %d)", binary, state.PID)
+
+		exited := make(chan error, 1)
+		go func() { exited <- cmd.Wait() }()
+
+		select {
+		case <-stop:
+			logw.Event("stopping %s (pid %d)", binary, state.PID)
+			terminate(state.PID)
+			select {
+			case <-exited:
+			case <-time.After(stopTimeout):
+				logw.Event("server ignored the stop signal, killing it")
+				cmd.Process.Kill()
+				<-exited
+			}
+			logw.Event("server stopped")
+			return nil
+
+		case err := <-exited:
+			state.LastExit = exitReason(err)
+			if time.Since(state.StartedAt) >= stableAfter {
+				backoff, crashes = minBackoff, 0
+			}
+			crashes++
+			if crashes >= maxCrashes {
+				logw.Event("server exited (%s); giving up after %d crashes in a row", state.LastExit, crashes)
+				return fmt.Errorf("server keeps crashing: %s", state.LastExit)
+			}
+			logw.Event("server exited (%s), restarting in %v", state.LastExit, backoff)
+			state.PID = 0
+			state.Restarts++
+			writeState(state)
+		}
+
+		select {
+		case <-stop:
+			logw.Event("stopped while waiting to restart")
+			return nil
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+func exitReason(err error) string {
+	if err == nil {
+		return "exit status 0"
+	}
+	return err.Error()
+}
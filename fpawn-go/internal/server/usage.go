@@ -0,0 +1,21 @@
+package server
+
+import "time"
+
+// ProcessUsage is a snapshot of a process's resource consumption
+type ProcessUsage struct {
+	PID int `json:"pid"`
+	// CPUTime is the user plus system time consumed since the process started
+	CPUTime time.Duration `json:"cpu_time"`
+	// RSS is the resident set size in bytes
+	RSS int64 `json:"rss"`
+}
+
+// CPUPercent is the share of one core used between two snapshots of the
+// same process taken wall apart
+func CPUPercent(prev, cur *ProcessUsage, wall time.Duration) float64 {
+	if prev == nil || cur == nil || prev.PID != cur.PID || wall <= 0 {
+		return 0
+	}
+	return float64(cur.CPUTime-prev.CPUTime) / float64(wall) * 100
+}
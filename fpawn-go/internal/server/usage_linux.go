@@ -0,0 +1,58 @@
+//go:build linux
+
+package server
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// clockTicks is USER_HZ, the unit of utime/stime in /proc/<pid>/stat. It is
+// 100 on every Linux architecture the server runs on.
+const clockTicks = 100
+
+// Usage reads the CPU time and resident memory of pid from /proc
+func Usage(pid int) (*ProcessUsage, error) {
+	dir := filepath.Join("/proc", strconv.Itoa(pid))
+
+	stat, err := os.ReadFile(filepath.Join(dir, "stat"))
+	if err != nil {
+		return nil, err
+	}
+	// The command name may contain spaces, so fields start after its ")"
+	end := strings.LastIndexByte(string(stat), ')')
+	if end < 0 {
+		return nil, fmt.Errorf("unexpected /proc/%d/stat format", pid)
+	}
+	fields := strings.Fields(string(stat[end+1:]))
+	// utime and stime are fields 14 and 15; fields[0] here is field 3
+	if len(fields) < 13 {
+		return nil, fmt.Errorf("unexpected /proc/%d/stat format", pid)
+	}
+	utime, _ := strconv.ParseInt(fields[11], 10, 64)
+	stime, _ := strconv.ParseInt(fields[12], 10, 64)
+
+	usage := &ProcessUsage{
+		PID:     pid,
+		CPUTime: time.Duration(utime+stime) * time.Second / clockTicks,
+	}
+
+	status, err := os.ReadFile(filepath.Join(dir, "status"))
+	if err != nil {
+		return nil, err
+	}
+	for _, line := range strings.Split(string(status), "\n") {
+		if strings.HasPrefix(line, "VmRSS:") {
+			// "VmRSS:	  123456 kB"
+			if f := strings.Fields(line); len(f) >= 2 {
+				kb, _ := strconv.ParseInt(f[1], 10, 64)
+				usage.RSS = kb * 1024
+			}
+		}
+	}
+	return usage, nil
+}
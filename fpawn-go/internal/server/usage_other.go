@@ -0,0 +1,10 @@
+//go:build !linux
+
+package server
+
+import "fmt"
+
+// Usage needs /proc, which only Linux provides
+func Usage(pid int) (*ProcessUsage, error) {
+	return nil, fmt.Errorf("process statistics are only available on Linux")
+}
@@ -0,0 +1,123 @@
+package servercfg
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/FerzDevZ/fpawn/internal/core"
+)
+
+// ShowSettings prints the typed configuration of the server in the
+// working directory
+func ShowSettings() (*Settings, error) {
+	c, err := Open()
+	if err != nil {
+		return nil, err
+	}
+	s := c.Settings()
+
+	list := func(values []string) string {
+		if len(values) == 0 {
+			return core.Yellow("(none)")
+		}
+		return strings.Join(values, ", ")
+	}
+	rcon := core.Yellow("OFF")
+	if s.RCON {
+		rcon = core.Green("ON")
+	}
+
+	fmt.Printf(" %s %s\n", core.Bold("File:"), s.Path)
+	fmt.Printf(" %s %s\n", core.Bold("Hostname:"), s.Hostname)
+	fmt.Printf(" %s %d\n", core.Bold("Port:"), s.Port)
+	fmt.Printf(" %s %d\n", core.Bold("Max players:"), s.MaxPlayers)
+	fmt.Printf(" %s %s\n", core.Bold("Language:"), s.Language)
+	fmt.Printf(" %s %s\n", core.Bold("RCON:"), rcon)
+	fmt.Printf(" %s %s\n", core.Bold("Gamemodes:"), list(s.Gamemodes))
+	fmt.Printf(" %s %s\n", core.Bold("Filterscripts:"), list(s.Filterscripts))
+	fmt.Printf(" %s %s\n", core.Bold("Plugins:"), list(s.Plugins))
+	return s, nil
+}
+
+// SetValue changes one key and saves the file
+func SetValue(key, value string) error {
+	c, err := Open()
+	if err != nil {
+		return err
+	}
+	if err := c.Set(key, value); err != nil {
+		return err
+	}
+	if err := c.Save(); err != nil {
+		return err
+	}
+	fmt.Printf(" %s %s = %s in %s\n", core.Green("✓"), key, value, c.Path)
+	return nil
+}
+
+// AddPluginEntry loads a plugin from the server configuration
+func AddPluginEntry(name string) error {
+	c, err := Open()
+	if err != nil {
+		return err
+	}
+	added, err := c.AddPlugin(name)
+	if err != nil {
+		return err
+	}
+	if !added {
+		fmt.Printf(" %s %s is already in %s\n", core.Cyan("[Skip]"), name, c.Path)
+		return nil
+	}
+	if err := c.Save(); err != nil {
+		return err
+	}
+	fmt.Printf(" %s Added %s to %s\n", core.Blue("[Config]"), name, c.Path)
+	return nil
+}
+
+// RemovePluginEntry stops a plugin from being loaded
+func RemovePluginEntry(name string) error {
+	c, err := Open()
+	if err != nil {
+		return err
+	}
+	removed, err := c.RemovePlugin(name)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return fmt.Errorf("%s is not loaded by %s", name, c.Path)
+	}
+	if err := c.Save(); err != nil {
+		return err
+	}
+	fmt.Printf(" %s Removed %s from %s\n", core.Blue("[Config]"), name, c.Path)
+	return nil
+}
+
+// MigrateConfig converts server.cfg to config.json (or back, with reverse)
+// and prints which keys were carried over. dryRun prints the result instead
+// of writing it.
+func MigrateConfig(reverse, force, dryRun bool) (*Migration, error) {
+	dst, m, err := Migrate(".", reverse, force, dryRun)
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Printf("\n %s %s\n", core.LBlue("🔁"), core.Bold("Config Migration"))
+	fmt.Println(" ──────────────────────────────────────────────────")
+	fmt.Printf(" %s %s → %s\n", core.Cyan("[Migrate]"), m.From, m.To)
+	fmt.Printf(" %s %d setting(s) converted\n", core.Green("✓"), m.Converted)
+	for _, key := range m.Unmapped {
+		fmt.Printf(" %s %s has no equivalent and was left out\n", core.Yellow("[Skip]"), key)
+	}
+
+	if dryRun {
+		fmt.Printf("\n %s %s would contain:\n\n", core.Cyan("[Dry Run]"), m.To)
+		fmt.Println(string(dst.Encode()))
+		return m, nil
+	}
+	fmt.Printf(" %s Wrote %s\n\n", core.Green("✓"), m.To)
+	return m, nil
+}
@@ -0,0 +1,148 @@
+package servercfg
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// gamemodeKey matches the numbered gamemode lines (gamemode0 ... gamemode15)
+var gamemodeKey = regexp.MustCompile(`^gamemode\d+$`)
+
+// legacyDoc keeps server.cfg as its original lines so that everything it
+// does not edit, comments and echo lines included, survives a rewrite
+type legacyDoc struct {
+	lines []string
+	crlf  bool
+}
+
+func parseLegacy(data []byte) *legacyDoc {
+	text := string(data)
+	d := &legacyDoc{crlf: strings.Contains(text, "\r\n")}
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	d.lines = strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+	if text == "" {
+		d.lines = nil
+	}
+	return d
+}
+
+// key returns the key of line i, or "" for blank and comment lines
+func (d *legacyDoc) key(i int) string {
+	fields := strings.Fields(d.lines[i])
+	if len(fields) == 0 || strings.HasPrefix(fields[0], "#") || strings.HasPrefix(fields[0], "//") {
+		return ""
+	}
+	return strings.ToLower(fields[0])
+}
+
+// value is everything after the key, so hostnames keep their spaces
+func (d *legacyDoc) value(i int) string {
+	line := strings.TrimSpace(d.lines[i])
+	if sp := strings.IndexAny(line, " \t"); sp >= 0 {
+		return strings.TrimSpace(line[sp:])
+	}
+	return ""
+}
+
+func (d *legacyDoc) get(key string) (string, bool) {
+	for i := range d.lines {
+		if d.key(i) == key {
+			return d.value(i), true
+		}
+	}
+	return "", false
+}
+
+func (d *legacyDoc) set(key, value string) error {
+	if strings.ContainsAny(key, " \t") || key == "" {
+		return fmt.Errorf("invalid server.cfg key %q", key)
+	}
+	found := false
+	for i := range d.lines {
+		if d.key(i) == key {
+			d.lines[i] = key + " " + value
+			found = true
+		}
+	}
+	if !found {
+		d.lines = append(d.lines, key+" "+value)
+	}
+	return nil
+}
+
+func (d *legacyDoc) list(key string) []string {
+	var values []string
+	for i := range d.lines {
+		k := d.key(i)
+		switch {
+		case key == "gamemodes" && gamemodeKey.MatchString(k):
+			if fields := strings.Fields(d.value(i)); len(fields) > 0 {
+				values = append(values, fields[0])
+			}
+		case k == key:
+			values = append(values, strings.Fields(d.value(i))...)
+		}
+	}
+	return values
+}
+
+// setList rewrites a list in place: the first line holding it is replaced
+// and any later ones are dropped, so a key split over several lines ends
+// up on one
+func (d *legacyDoc) setList(key string, values []string) error {
+	matches := func(i int) bool {
+		if key == "gamemodes" {
+			return gamemodeKey.MatchString(d.key(i))
+		}
+		return d.key(i) == key
+	}
+
+	var replacement []string
+	if key == "gamemodes" {
+		repeats := map[string]string{}
+		for i := range d.lines {
+			if fields := strings.Fields(d.value(i)); matches(i) && len(fields) > 1 {
+				repeats[fields[0]] = fields[1]
+			}
+		}
+		for n, name := range values {
+			repeat := repeats[name]
+			if repeat == "" {
+				repeat = "1"
+			}
+			replacement = append(replacement, fmt.Sprintf("gamemode%d %s %s", n, name, repeat))
+		}
+	} else {
+		replacement = []string{strings.TrimSpace(key + " " + strings.Join(values, " "))}
+	}
+
+	var lines []string
+	placed := false
+	for i, line := range d.lines {
+		if !matches(i) {
+			lines = append(lines, line)
+			continue
+		}
+		if !placed {
+			lines = append(lines, replacement...)
+			placed = true
+		}
+	}
+	if !placed {
+		lines = append(lines, replacement...)
+	}
+	d.lines = lines
+	return nil
+}
+
+func (d *legacyDoc) encode() []byte {
+	nl := "\n"
+	if d.crlf {
+		nl = "\r\n"
+	}
+	if len(d.lines) == 0 {
+		return nil
+	}
+	return []byte(strings.Join(d.lines, nl) + nl)
+}
@@ -0,0 +1,291 @@
+package servercfg
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// valueKind is the JSON type a server.cfg value becomes in config.json
+type valueKind int
+
+const (
+	kindString valueKind = iota
+	kindInt
+	kindFloat
+	kindBool
+)
+
+// mapping pairs a server.cfg key with its config.json path
+type mapping struct {
+	legacy string
+	path   string
+	kind   valueKind
+}
+
+// migrationTable lists the server.cfg keys open.mp has an equivalent for,
+// in the order they are written out. The gamemode, filterscript and plugin
+// lists are handled separately.
+var migrationTable = []mapping{
+	{"hostname", "name", kindString},
+	{"language", "language", kindString},
+	{"maxplayers", "max_players", kindInt},
+	{"maxnpc", "max_bots", kindInt},
+	{"password", "password", kindString},
+	{"announce", "announce", kindBool},
+	{"query", "enable_query", kindBool},
+	{"weburl", "website", kindString},
+	{"sleep", "sleep", kindFloat},
+	{"gamemodetext", "game.mode", kindString},
+	{"mapname", "game.map", kindString},
+	{"lagcompmode", "game.lag_compensation_mode", kindInt},
+	{"port", "network.port", kindInt},
+	{"bind", "network.bind", kindString},
+	{"lanmode", "network.use_lan_mode", kindBool},
+	{"mtu", "network.mtu", kindInt},
+	{"stream_distance", "network.stream_radius", kindFloat},
+	{"stream_rate", "network.stream_rate", kindInt},
+	{"onfoot_rate", "network.on_foot_sync_rate", kindInt},
+	{"incar_rate", "network.in_vehicle_sync_rate", kindInt},
+	{"weapon_rate", "network.aiming_sync_rate", kindInt},
+	{"playertimeout", "network.player_timeout", kindInt},
+	{"messageslimit", "network.messages_limit", kindInt},
+	{"messageholelimit", "network.message_hole_limit", kindInt},
+	{"ackslimit", "network.acks_limit", kindInt},
+	{"minconnectiontime", "network.minimum_connection_time", kindInt},
+	{"connseedtime", "network.cookie_reseed_time", kindInt},
+	{"chatlogging", "logging.log_chat", kindBool},
+	{"timestamp", "logging.use_timestamp", kindBool},
+	{"logtimeformat", "logging.timestamp_format", kindString},
+	{"logqueries", "logging.log_queries", kindBool},
+	{"db_logging", "logging.log_sqlite", kindBool},
+	{"db_log_queries", "logging.log_sqlite_queries", kindBool},
+	{"rcon", "rcon.enable", kindBool},
+	{"rcon_password", "rcon.password", kindString},
+}
+
+// Migration reports what a conversion did
+type Migration struct {
+	From      string `json:"from"`
+	To        string `json:"to"`
+	Converted int    `json:"converted"`
+	// Unmapped lists the keys that have no equivalent in the target format
+	// and were left out, with the reason when it is not just that
+	Unmapped []string `json:"unmapped"`
+}
+
+// Convert translates a configuration into the other format. The result is
+// a new, unsaved Config next to the source (server.cfg becomes config.json
+// and the other way round); the source is not modified.
+func Convert(src *Config) (*Config, *Migration, error) {
+	switch doc := src.doc.(type) {
+	case *legacyDoc:
+		dst := &Config{Path: filepath.Join(filepath.Dir(src.Path), string(OpenMP)), Format: OpenMP}
+		m := &Migration{From: src.Path, To: dst.Path, Unmapped: []string{}}
+		dst.doc = legacyToOpenMP(doc, m)
+		return dst, m, nil
+	case *openMPDoc:
+		dst := &Config{Path: filepath.Join(filepath.Dir(src.Path), string(Legacy)), Format: Legacy}
+		m := &Migration{From: src.Path, To: dst.Path, Unmapped: []string{}}
+		dst.doc = openMPToLegacy(doc, m)
+		return dst, m, nil
+	}
+	return nil, nil, fmt.Errorf("%s: unknown configuration format", src.Path)
+}
+
+func legacyToOpenMP(src *legacyDoc, m *Migration) *openMPDoc {
+	dst := newOpenMP()
+	byKey := map[string]mapping{}
+	for _, e := range migrationTable {
+		byKey[e.legacy] = e
+	}
+
+	values := map[string]string{}
+	var gamemodes []string
+	for i := range src.lines {
+		key := src.key(i)
+		switch {
+		case key == "":
+		case gamemodeKey.MatchString(key):
+			// "gamemode0 rp 1" becomes "rp 1"
+			if v := src.value(i); v != "" {
+				gamemodes = append(gamemodes, strings.Join(strings.Fields(v), " "))
+			}
+		case key == "plugins", key == "filterscripts":
+		case key == "echo":
+			// Console output while loading; open.mp has no counterpart
+		default:
+			if _, ok := byKey[key]; ok {
+				values[key] = src.value(i)
+			} else {
+				m.Unmapped = append(m.Unmapped, key)
+			}
+		}
+	}
+
+	for _, e := range migrationTable {
+		v, ok := values[e.legacy]
+		if !ok {
+			continue
+		}
+		node, err := legacyValueNode(v, e.kind)
+		if err != nil {
+			m.Unmapped = append(m.Unmapped, fmt.Sprintf("%s (%v)", e.legacy, err))
+			continue
+		}
+		*dst.lookup(e.path, true) = *node
+		m.Converted++
+	}
+
+	if len(gamemodes) > 0 {
+		setStrings(dst.lookup("pawn.main_scripts", true), gamemodes)
+		m.Converted++
+	}
+	for _, key := range []string{"filterscripts", "plugins"} {
+		if values := src.list(key); len(values) > 0 {
+			if key == "plugins" {
+				// open.mp finds legacy plugins without their extension
+				for i, p := range values {
+					values[i] = pluginName(p)
+				}
+			}
+			dst.setList(key, values)
+			m.Converted++
+		}
+	}
+	return dst
+}
+
+func legacyValueNode(v string, kind valueKind) (*yaml.Node, error) {
+	n := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: v}
+	switch kind {
+	case kindInt:
+		if _, err := strconv.Atoi(v); err != nil {
+			return nil, fmt.Errorf("%q is not a whole number", v)
+		}
+		n.Tag = "!!int"
+	case kindFloat:
+		if _, err := strconv.ParseFloat(v, 64); err != nil {
+			return nil, fmt.Errorf("%q is not a number", v)
+		}
+		n.Tag = "!!float"
+	case kindBool:
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not 0 or 1", v)
+		}
+		n.Tag, n.Value = "!!bool", strconv.FormatBool(b)
+	}
+	return n, nil
+}
+
+func openMPToLegacy(src *openMPDoc, m *Migration) *legacyDoc {
+	dst := &legacyDoc{}
+	known := map[string]bool{}
+
+	for _, e := range migrationTable {
+		known[e.path] = true
+		n := src.lookup(e.path, false)
+		if n == nil || n.Kind != yaml.ScalarNode {
+			continue
+		}
+		v := n.Value
+		if e.kind == kindBool {
+			v = "0"
+			if b, _ := strconv.ParseBool(n.Value); b {
+				v = "1"
+			}
+		}
+		dst.set(e.legacy, v)
+		m.Converted++
+	}
+
+	for _, path := range openMPLists {
+		known[path] = true
+	}
+	if n := src.lookup("pawn.main_scripts", false); n != nil && n.Kind == yaml.SequenceNode {
+		for i, item := range n.Content {
+			entry := item.Value
+			if len(strings.Fields(entry)) == 1 {
+				entry += " 1"
+			}
+			dst.lines = append(dst.lines, fmt.Sprintf("gamemode%d %s", i, entry))
+		}
+		m.Converted++
+	}
+	if values := src.list("filterscripts"); len(values) > 0 {
+		dst.setList("filterscripts", values)
+		m.Converted++
+	}
+	if values := src.list("plugins"); len(values) > 0 {
+		// The SA-MP Linux server only loads plugins named with their extension
+		if runtime.GOOS != "windows" {
+			for i, p := range values {
+				if filepath.Ext(p) == "" {
+					values[i] = p + ".so"
+				}
+			}
+		}
+		dst.setList("plugins", values)
+		m.Converted++
+	}
+
+	for _, path := range leafPaths(src.root, "") {
+		if !known[path] {
+			m.Unmapped = append(m.Unmapped, path)
+		}
+	}
+	return dst
+}
+
+// leafPaths lists the dotted paths of every value in a config.json tree;
+// arrays count as values
+func leafPaths(n *yaml.Node, prefix string) []string {
+	if n.Kind != yaml.MappingNode {
+		return []string{prefix}
+	}
+	var paths []string
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		path := n.Content[i].Value
+		if prefix != "" {
+			path = prefix + "." + path
+		}
+		paths = append(paths, leafPaths(n.Content[i+1], path)...)
+	}
+	return paths
+}
+
+// Migrate converts server.cfg in dir to config.json, or config.json back to
+// server.cfg when reverse is set, and writes the result unless dryRun is
+// set. An existing target is only replaced when force is set.
+func Migrate(dir string, reverse, force, dryRun bool) (*Config, *Migration, error) {
+	from := Legacy
+	if reverse {
+		from = OpenMP
+	}
+	src, err := LoadFile(filepath.Join(dir, string(from)))
+	if err != nil {
+		return nil, nil, err
+	}
+	dst, m, err := Convert(src)
+	if err != nil {
+		return nil, nil, err
+	}
+	if dryRun {
+		return dst, m, nil
+	}
+	if _, err := os.Stat(dst.Path); err == nil && !force {
+		return nil, nil, fmt.Errorf("%s already exists (use --force to overwrite it)", dst.Path)
+	}
+	return dst, m, dst.Save()
+}
+
+// Encode returns the file contents Save would write
+func (c *Config) Encode() []byte {
+	return c.doc.encode()
+}
@@ -0,0 +1,232 @@
+package servercfg
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/FerzDevZ/fpawn/internal/manifest"
+	"gopkg.in/yaml.v3"
+)
+
+// openMPLists maps the list keys to their config.json arrays
+var openMPLists = map[string]string{
+	"gamemodes":     "pawn.main_scripts",
+	"filterscripts": "pawn.side_scripts",
+	"plugins":       "pawn.legacy_plugins",
+}
+
+// openMPDoc edits config.json as a node tree, the same way pawn.json is
+// edited, so keys keep their order
+type openMPDoc struct {
+	root   *yaml.Node
+	indent string
+}
+
+// openMPIndent is what open.mp itself writes config.json with
+const openMPIndent = "    "
+
+func newOpenMP() *openMPDoc {
+	return &openMPDoc{root: &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}, indent: openMPIndent}
+}
+
+func parseOpenMP(data []byte) (*openMPDoc, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 {
+		return newOpenMP(), nil
+	}
+	if doc.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("top level is not an object")
+	}
+	return &openMPDoc{root: doc.Content[0], indent: manifest.DetectIndent(data)}, nil
+}
+
+// lookup follows a dotted path, creating missing objects when create is set
+func (d *openMPDoc) lookup(path string, create bool) *yaml.Node {
+	n := d.root
+	for _, part := range strings.Split(path, ".") {
+		if n.Kind != yaml.MappingNode {
+			return nil
+		}
+		var next *yaml.Node
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			if n.Content[i].Value == part {
+				next = n.Content[i+1]
+				break
+			}
+		}
+		if next == nil {
+			if !create {
+				return nil
+			}
+			next = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			n.Content = append(n.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: part}, next)
+		}
+		n = next
+	}
+	return n
+}
+
+// notSection explains why lookup could not create path: one of the parts
+// leading to it holds a value or a list instead of an object
+func (d *openMPDoc) notSection(path string) error {
+	parts := strings.Split(path, ".")
+	for i := 1; i < len(parts); i++ {
+		prefix := strings.Join(parts[:i], ".")
+		if n := d.lookup(prefix, false); n != nil && n.Kind != yaml.MappingNode {
+			return fmt.Errorf("%s is not a section", prefix)
+		}
+	}
+	return fmt.Errorf("%s is not a section", path)
+}
+
+func (d *openMPDoc) get(key string) (string, bool) {
+	n := d.lookup(key, false)
+	if n == nil {
+		return "", false
+	}
+	switch n.Kind {
+	case yaml.ScalarNode:
+		return n.Value, true
+	case yaml.SequenceNode:
+		var values []string
+		for _, item := range n.Content {
+			values = append(values, item.Value)
+		}
+		return strings.Join(values, " "), true
+	}
+	return "", false
+}
+
+// set keeps the JSON type of an existing value; new values are numbers or
+// booleans when they look like one and strings otherwise
+func (d *openMPDoc) set(key, value string) error {
+	n := d.lookup(key, true)
+	if n == nil {
+		return d.notSection(key)
+	}
+	switch n.Kind {
+	case yaml.SequenceNode:
+		setStrings(n, strings.Fields(value))
+		return nil
+	case yaml.MappingNode:
+		if len(n.Content) > 0 {
+			return fmt.Errorf("%s is a section, not a value", key)
+		}
+	}
+
+	tag := n.Tag
+	if n.Kind != yaml.ScalarNode {
+		tag = inferTag(value)
+	}
+	switch tag {
+	case "!!int":
+		if _, err := strconv.Atoi(value); err != nil {
+			return fmt.Errorf("%s must be a whole number", key)
+		}
+	case "!!float":
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return fmt.Errorf("%s must be a number", key)
+		}
+	case "!!bool":
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%s must be true or false", key)
+		}
+		value = strconv.FormatBool(b)
+	default:
+		tag = "!!str"
+	}
+	*n = yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: value}
+	return nil
+}
+
+func inferTag(value string) string {
+	if _, err := strconv.Atoi(value); err == nil {
+		return "!!int"
+	}
+	if value == "true" || value == "false" {
+		return "!!bool"
+	}
+	return "!!str"
+}
+
+// list reads one of the list keys. main_scripts entries carry a repeat
+// count and side_scripts a "filterscripts/" prefix; both are dropped.
+func (d *openMPDoc) list(key string) []string {
+	path, ok := openMPLists[key]
+	if !ok {
+		return nil
+	}
+	n := d.lookup(path, false)
+	if n == nil || n.Kind != yaml.SequenceNode {
+		return nil
+	}
+	var values []string
+	for _, item := range n.Content {
+		v := item.Value
+		switch key {
+		case "gamemodes":
+			if fields := strings.Fields(v); len(fields) > 0 {
+				v = fields[0]
+			}
+		case "filterscripts":
+			v = strings.TrimPrefix(v, "filterscripts/")
+		}
+		values = append(values, v)
+	}
+	return values
+}
+
+func (d *openMPDoc) setList(key string, values []string) error {
+	path, ok := openMPLists[key]
+	if !ok {
+		return fmt.Errorf("%s is not a list", key)
+	}
+	n := d.lookup(path, true)
+	if n == nil {
+		return d.notSection(path)
+	}
+
+	entries := make([]string, len(values))
+	switch key {
+	case "gamemodes":
+		repeats := map[string]string{}
+		if n.Kind == yaml.SequenceNode {
+			for _, item := range n.Content {
+				if fields := strings.Fields(item.Value); len(fields) > 1 {
+					repeats[fields[0]] = fields[1]
+				}
+			}
+		}
+		for i, v := range values {
+			repeat := repeats[v]
+			if repeat == "" {
+				repeat = "1"
+			}
+			entries[i] = v + " " + repeat
+		}
+	case "filterscripts":
+		for i, v := range values {
+			entries[i] = "filterscripts/" + v
+		}
+	default:
+		copy(entries, values)
+	}
+	setStrings(n, entries)
+	return nil
+}
+
+func setStrings(n *yaml.Node, values []string) {
+	*n = yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+	for _, v := range values {
+		n.Content = append(n.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: v})
+	}
+}
+
+func (d *openMPDoc) encode() []byte {
+	return manifest.EncodeJSON(d.root, d.indent)
+}
@@ -0,0 +1,254 @@
+package servercfg
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Format tells the two server configuration files apart
+type Format string
+
+const (
+	// Legacy is the SA-MP server.cfg: one "key value" pair per line
+	Legacy Format = "server.cfg"
+	// OpenMP is the open.mp config.json
+	OpenMP Format = "config.json"
+)
+
+// ErrNotFound is returned when a directory has neither config file
+var ErrNotFound = errors.New("no config.json or server.cfg found")
+
+// Settings is the typed view of the values fpawn cares about
+type Settings struct {
+	Format        Format   `json:"format"`
+	Path          string   `json:"path"`
+	Hostname      string   `json:"hostname"`
+	Port          int      `json:"port"`
+	MaxPlayers    int      `json:"max_players"`
+	Language      string   `json:"language"`
+	RCON          bool     `json:"rcon"`
+	RCONPassword  string   `json:"rcon_password"`
+	Gamemodes     []string `json:"gamemodes"`
+	Filterscripts []string `json:"filterscripts"`
+	Plugins       []string `json:"plugins"`
+}
+
+// document is one file format. Keys are already translated to the
+// format's own names; list keys are always "gamemodes", "filterscripts"
+// and "plugins".
+type document interface {
+	get(key string) (string, bool)
+	set(key, value string) error
+	list(key string) []string
+	setList(key string, values []string) error
+	encode() []byte
+}
+
+// Config is a server configuration file loaded for reading and editing.
+// Lines, keys and comments it does not touch are written back unchanged.
+type Config struct {
+	Path   string
+	Format Format
+	doc    document
+}
+
+// aliases maps fpawn's key names to the server.cfg and config.json ones.
+// Keys that are not listed are passed through as they are, so any
+// server.cfg key or dotted config.json path works too.
+var aliases = map[string][2]string{
+	"hostname":      {"hostname", "name"},
+	"port":          {"port", "network.port"},
+	"bind":          {"bind", "network.bind"},
+	"maxplayers":    {"maxplayers", "max_players"},
+	"language":      {"language", "language"},
+	"password":      {"password", "password"},
+	"announce":      {"announce", "announce"},
+	"query":         {"query", "enable_query"},
+	"weburl":        {"weburl", "website"},
+	"rcon":          {"rcon", "rcon.enable"},
+	"rcon_password": {"rcon_password", "rcon.password"},
+}
+
+// listKeys are the space-separated list values
+var listKeys = map[string]bool{"gamemodes": true, "filterscripts": true, "plugins": true}
+
+// Keys returns the key names Get and Set understand in every format
+func Keys() []string {
+	var keys []string
+	for k := range aliases {
+		keys = append(keys, k)
+	}
+	for k := range listKeys {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Load reads the server configuration in dir. open.mp reads config.json in
+// preference to server.cfg, and so does Load.
+func Load(dir string) (*Config, error) {
+	for _, name := range []Format{OpenMP, Legacy} {
+		path := filepath.Join(dir, string(name))
+		if _, err := os.Stat(path); err == nil {
+			return LoadFile(path)
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// Open loads the configuration of the server in the working directory
+func Open() (*Config, error) {
+	return Load(".")
+}
+
+// LoadFile parses one configuration file, choosing the format by name
+func LoadFile(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Config{Path: path, Format: Legacy}
+	if filepath.Ext(path) == ".json" {
+		c.Format = OpenMP
+		c.doc, err = parseOpenMP(data)
+	} else {
+		c.doc = parseLegacy(data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return c, nil
+}
+
+// resolve translates an fpawn key to the file format's own name
+func (c *Config) resolve(key string) string {
+	if names, ok := aliases[key]; ok {
+		if c.Format == OpenMP {
+			return names[1]
+		}
+		return names[0]
+	}
+	return key
+}
+
+// Get returns a value as text; lists are joined with spaces
+func (c *Config) Get(key string) (string, bool) {
+	if listKeys[key] {
+		values := c.doc.list(key)
+		return strings.Join(values, " "), len(values) > 0
+	}
+	return c.doc.get(c.resolve(key))
+}
+
+// Set changes a value, adding the key when the file does not have it yet.
+// List keys take a space-separated value.
+func (c *Config) Set(key, value string) error {
+	if listKeys[key] {
+		return c.doc.setList(key, strings.Fields(value))
+	}
+	return c.doc.set(c.resolve(key), value)
+}
+
+// Gamemodes returns the gamemodes in load order, without repeat counts
+func (c *Config) Gamemodes() []string {
+	return c.doc.list("gamemodes")
+}
+
+// Filterscripts returns the filterscripts in load order
+func (c *Config) Filterscripts() []string {
+	return c.doc.list("filterscripts")
+}
+
+// Plugins returns the plugins as written, extension included if any
+func (c *Config) Plugins() []string {
+	return c.doc.list("plugins")
+}
+
+// HasPlugin reports whether name is loaded, ignoring .so/.dll extensions
+func (c *Config) HasPlugin(name string) bool {
+	for _, p := range c.Plugins() {
+		if pluginName(p) == pluginName(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// AddPlugin appends name to the plugin list and reports whether it was
+// missing. The extension follows the entries already there.
+func (c *Config) AddPlugin(name string) (bool, error) {
+	if c.HasPlugin(name) {
+		return false, nil
+	}
+	plugins := c.Plugins()
+	entry := pluginName(name)
+	for _, p := range plugins {
+		if ext := filepath.Ext(p); ext == ".so" || ext == ".dll" {
+			entry += ext
+			break
+		}
+	}
+	if err := c.doc.setList("plugins", append(plugins, entry)); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// RemovePlugin drops every entry naming the plugin and reports whether
+// there was one. Other plugins whose names merely contain it are kept.
+func (c *Config) RemovePlugin(name string) (bool, error) {
+	var kept []string
+	for _, p := range c.Plugins() {
+		if pluginName(p) != pluginName(name) {
+			kept = append(kept, p)
+		}
+	}
+	if len(kept) == len(c.Plugins()) {
+		return false, nil
+	}
+	if err := c.doc.setList("plugins", kept); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Save writes the configuration back to Path
+func (c *Config) Save() error {
+	return os.WriteFile(c.Path, c.Encode(), 0644)
+}
+
+// Settings collects the typed values
+func (c *Config) Settings() *Settings {
+	s := &Settings{
+		Format:        c.Format,
+		Path:          c.Path,
+		Gamemodes:     c.Gamemodes(),
+		Filterscripts: c.Filterscripts(),
+		Plugins:       c.Plugins(),
+	}
+	s.Hostname, _ = c.Get("hostname")
+	s.Language, _ = c.Get("language")
+	s.RCONPassword, _ = c.Get("rcon_password")
+	if v, ok := c.Get("port"); ok {
+		s.Port, _ = strconv.Atoi(v)
+	}
+	if v, ok := c.Get("maxplayers"); ok {
+		s.MaxPlayers, _ = strconv.Atoi(v)
+	}
+	if v, ok := c.Get("rcon"); ok {
+		s.RCON = v == "1" || v == "true"
+	}
+	return s
+}
+
+// pluginName strips the platform extension from a plugin entry
+func pluginName(p string) string {
+	return strings.TrimSuffix(strings.TrimSuffix(p, ".so"), ".dll")
+}
@@ -0,0 +1,151 @@
+package toolchain
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// extractArchive unpacks a .tar.gz or .zip release into dest, dropping the
+// single top-level directory pawn-lang wraps its archives in
+func extractArchive(archive, name, dest string) error {
+	lower := strings.ToLower(name)
+	if strings.HasSuffix(lower, ".zip") {
+		return extractZip(archive, dest)
+	}
+	return extractTarGz(archive, dest)
+}
+
+func extractTarGz(archive, dest string) error {
+	f, err := os.Open(archive)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		target, err := safeJoin(dest, stripTop(hdr.Name))
+		if err != nil {
+			return err
+		}
+		if target == "" {
+			continue
+		}
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := writeFile(target, tr, os.FileMode(hdr.Mode)); err != nil {
+				return err
+			}
+		case tar.TypeSymlink:
+			// Only keep links that stay next to their source (libpawnc.so -> libpawnc.so.3)
+			if strings.Contains(hdr.Linkname, "/") || strings.Contains(hdr.Linkname, "\\") {
+				continue
+			}
+			os.MkdirAll(filepath.Dir(target), 0755)
+			os.Remove(target)
+			if err := os.Symlink(hdr.Linkname, target); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func extractZip(archive, dest string) error {
+	r, err := zip.OpenReader(archive)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	for _, f := range r.File {
+		target, err := safeJoin(dest, stripTop(f.Name))
+		if err != nil {
+			return err
+		}
+		if target == "" {
+			continue
+		}
+
+		if f.FileInfo().IsDir() {
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+			continue
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return err
+		}
+		err = writeFile(target, rc, f.Mode())
+		rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// stripTop removes the leading "pawnc-<version>-<os>/" component
+func stripTop(name string) string {
+	name = strings.TrimPrefix(strings.ReplaceAll(name, "\\", "/"), "./")
+	if i := strings.Index(name, "/"); i != -1 && strings.HasPrefix(name, "pawnc") {
+		return name[i+1:]
+	}
+	return name
+}
+
+// safeJoin rejects entries that would escape dest
+func safeJoin(dest, name string) (string, error) {
+	if name == "" {
+		return "", nil
+	}
+	target := filepath.Join(dest, name)
+	if !strings.HasPrefix(target, filepath.Clean(dest)+string(os.PathSeparator)) {
+		return "", fmt.Errorf("illegal file path in archive: %s", name)
+	}
+	return target, nil
+}
+
+func writeFile(target string, r io.Reader, mode os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	if mode&0777 == 0 {
+		mode = 0644
+	}
+	out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode&0777)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, r); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
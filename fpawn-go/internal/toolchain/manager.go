@@ -0,0 +1,111 @@
+package toolchain
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/FerzDevZ/fpawn/internal/core"
+	"github.com/FerzDevZ/fpawn/internal/manifest"
+)
+
+// ProjectPin returns the compiler pin of the current project, or nil
+func ProjectPin() *manifest.CompilerPin {
+	if m := manifest.Current(); m != nil {
+		return m.CompilerFor(m.DefaultBuild())
+	}
+	return nil
+}
+
+// InstallToolchain installs a compiler release and prints the outcome.
+// An empty version installs the one the project manifest pins.
+func InstallToolchain(version string, src Source) (*Installation, error) {
+	fmt.Printf("\n %s %s\n", core.LBlue("🧰"), core.Bold("Toolchain Manager"))
+	fmt.Println(" ──────────────────────────────────────────────────")
+
+	expected := ""
+	pin := ProjectPin()
+	if version == "" {
+		if pin == nil {
+			return nil, fmt.Errorf("no version given and the project does not pin one")
+		}
+		version = pin.Version
+	}
+	version = Normalize(version)
+	if pin != nil && Normalize(pin.Version) == version {
+		expected = pin.SHA256
+	}
+
+	if inst := Installed(version); inst != nil && (expected == "" || inst.SHA256 == expected) {
+		fmt.Printf(" %s pawncc %s is already installed at %s\n", core.Green("[Skip]"), version, inst.Path())
+		return inst, nil
+	}
+
+	fmt.Printf(" %s Downloading pawncc %s...\n", core.Cyan("[Toolchain]"), version)
+	inst, err := Install(version, src, expected)
+	if err != nil {
+		return nil, err
+	}
+
+	if inst.Verified {
+		fmt.Printf(" %s sha256 %s\n", core.Green("[Verified]"), inst.SHA256)
+	} else {
+		fmt.Printf(" %s No published checksum for %s; recorded sha256 %s\n", core.Yellow("[Warn]"), inst.Asset, inst.SHA256)
+		fmt.Printf(" %s Pin it with: fpawn toolchain use %s\n", core.Cyan("[Tip]"), version)
+	}
+	fmt.Printf(" %s pawncc %s installed to %s\n", core.Green("✓"), version, inst.Path())
+	return inst, nil
+}
+
+// ListToolchains prints the installed compilers
+func ListToolchains() *Inventory {
+	inv := Inspect()
+
+	fmt.Printf("\n %s %s\n", core.LBlue("🧰"), core.Bold("Installed Compilers"))
+	fmt.Println(" ──────────────────────────────────────────────────")
+
+	if len(inv.Installed) == 0 {
+		fmt.Printf(" %s No compilers installed. Try: fpawn toolchain install 3.10.11\n", core.Yellow("[Info]"))
+	}
+	for _, inst := range inv.Installed {
+		var marks []string
+		if inst.Version == inv.Pinned {
+			marks = append(marks, core.Green("pinned"))
+		}
+		if inst.Version == inv.Default {
+			marks = append(marks, core.Cyan("default"))
+		}
+		status := core.Yellow(fmt.Sprintf("%-10s", "unverified"))
+		if inst.Verified {
+			status = core.Green(fmt.Sprintf("%-10s", "verified"))
+		}
+		fmt.Printf("   %-10s %s %s  %s\n", inst.Version, status, inst.SHA256[:12], strings.Join(marks, ", "))
+	}
+
+	if inv.Pinned != "" && Installed(inv.Pinned) == nil {
+		fmt.Printf("\n %s Project pins pawncc %s but it is not installed (fpawn toolchain install)\n", core.Red("[Missing]"), inv.Pinned)
+	}
+	fmt.Println()
+	return inv
+}
+
+// UseToolchain pins version in the project manifest, or makes it the user
+// default when global is set or there is no manifest
+func UseToolchain(version string, global bool) error {
+	version = Normalize(version)
+	m := manifest.Current()
+
+	if global || m == nil {
+		if err := SetDefault(version); err != nil {
+			return err
+		}
+		fmt.Printf(" %s pawncc %s is now the default compiler\n", core.Green("✓"), version)
+		return nil
+	}
+
+	pin, err := Pin(m, version)
+	if err != nil {
+		return err
+	}
+	fmt.Printf(" %s Pinned pawncc %s in %s (sha256 %s)\n", core.Green("✓"), pin.Version, m.Path, pin.SHA256[:12])
+	return nil
+}
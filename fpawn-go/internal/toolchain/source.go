@@ -0,0 +1,192 @@
+package toolchain
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"runtime"
+	"strings"
+)
+
+// Release is a downloadable compiler archive for the current platform
+type Release struct {
+	Version string `json:"version"`
+	Asset   string `json:"asset"`
+	URL     string `json:"url"`
+	// SHA256 is the checksum the source publishes for the asset, if any
+	SHA256 string `json:"sha256,omitempty"`
+}
+
+// Source locates and downloads compiler releases
+type Source interface {
+	Resolve(version string) (*Release, error)
+	Open(rel *Release) (io.ReadCloser, error)
+}
+
+// DefaultAPI is the GitHub API root used when no mirror is given
+const DefaultAPI = "https://api.github.com"
+
+// DefaultRepo publishes the community pawncc releases
+const DefaultRepo = "pawn-lang/compiler"
+
+// GitHubSource reads releases from a GitHub-compatible API. Point BaseURL
+// at a mirror or a local stand-in to install without reaching github.com.
+type GitHubSource struct {
+	BaseURL string
+	Repo    string
+	Client  *http.Client
+}
+
+// NewGitHubSource returns a source for pawn-lang/compiler behind baseURL,
+// or api.github.com when baseURL is empty
+func NewGitHubSource(baseURL string) *GitHubSource {
+	if baseURL == "" {
+		baseURL = DefaultAPI
+	}
+	return &GitHubSource{
+		BaseURL: strings.TrimSuffix(baseURL, "/"),
+		Repo:    DefaultRepo,
+		Client:  http.DefaultClient,
+	}
+}
+
+type githubRelease struct {
+	TagName string        `json:"tag_name"`
+	Assets  []githubAsset `json:"assets"`
+}
+
+type githubAsset struct {
+	Name        string `json:"name"`
+	DownloadURL string `json:"browser_download_url"`
+	Digest      string `json:"digest"`
+}
+
+// Resolve finds the release tagged v<version> (or <version>) and picks the
+// archive for this OS
+func (s *GitHubSource) Resolve(version string) (*Release, error) {
+	var rel *githubRelease
+	var lastErr error
+	for _, tag := range []string{"v" + version, version} {
+		r, err := s.fetchRelease(tag)
+		if err == nil {
+			rel = r
+			break
+		}
+		lastErr = err
+	}
+	if rel == nil {
+		return nil, fmt.Errorf("pawncc %s not found: %v", version, lastErr)
+	}
+
+	asset := pickAsset(rel.Assets)
+	if asset == nil {
+		return nil, fmt.Errorf("pawncc %s has no archive for %s", version, platformTag())
+	}
+
+	release := &Release{
+		Version: version,
+		Asset:   asset.Name,
+		URL:     asset.DownloadURL,
+		SHA256:  strings.TrimPrefix(asset.Digest, "sha256:"),
+	}
+	if release.SHA256 == "" {
+		release.SHA256 = s.publishedChecksum(rel.Assets, asset.Name)
+	}
+	return release, nil
+}
+
+// Open starts the download of rel
+func (s *GitHubSource) Open(rel *Release) (io.ReadCloser, error) {
+	resp, err := s.Client.Get(rel.URL)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("download %s: HTTP %d", rel.Asset, resp.StatusCode)
+	}
+	return resp.Body, nil
+}
+
+func (s *GitHubSource) fetchRelease(tag string) (*githubRelease, error) {
+	url := fmt.Sprintf("%s/repos/%s/releases/tags/%s", s.BaseURL, s.Repo, tag)
+	resp, err := s.Client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GitHub API returned status %d", resp.StatusCode)
+	}
+
+	var rel githubRelease
+	if err := json.NewDecoder(resp.Body).Decode(&rel); err != nil {
+		return nil, err
+	}
+	return &rel, nil
+}
+
+// publishedChecksum looks for "<asset>.sha256" or a SHA256SUMS-style file
+// among the release assets
+func (s *GitHubSource) publishedChecksum(assets []githubAsset, name string) string {
+	for _, a := range assets {
+		lower := strings.ToLower(a.Name)
+		if lower != strings.ToLower(name)+".sha256" && !strings.Contains(lower, "sha256sums") && lower != "checksums.txt" {
+			continue
+		}
+		resp, err := s.Client.Get(a.DownloadURL)
+		if err != nil {
+			continue
+		}
+		sum := findChecksum(resp.Body, name)
+		resp.Body.Close()
+		if sum != "" {
+			return sum
+		}
+	}
+	return ""
+}
+
+// findChecksum reads "<hex>  <file>" lines, or a bare hex digest
+func findChecksum(r io.Reader, name string) string {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		switch {
+		case len(fields) == 1 && len(fields[0]) == 64:
+			return strings.ToLower(fields[0])
+		case len(fields) >= 2 && strings.TrimPrefix(fields[1], "*") == name:
+			return strings.ToLower(fields[0])
+		}
+	}
+	return ""
+}
+
+func pickAsset(assets []githubAsset) *githubAsset {
+	tag := platformTag()
+	for i, a := range assets {
+		name := strings.ToLower(a.Name)
+		if strings.Contains(name, tag) && isArchive(name) {
+			return &assets[i]
+		}
+	}
+	return nil
+}
+
+// platformTag is the OS suffix pawn-lang uses in asset names
+func platformTag() string {
+	switch runtime.GOOS {
+	case "windows":
+		return "windows"
+	case "darwin":
+		return "macos"
+	}
+	return "linux"
+}
+
+func isArchive(name string) bool {
+	return strings.HasSuffix(name, ".tar.gz") || strings.HasSuffix(name, ".tgz") || strings.HasSuffix(name, ".zip")
+}
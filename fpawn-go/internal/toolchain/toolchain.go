@@ -0,0 +1,349 @@
+package toolchain
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/FerzDevZ/fpawn/internal/core"
+	"github.com/FerzDevZ/fpawn/internal/manifest"
+)
+
+// Installation describes a compiler release unpacked under Root()
+type Installation struct {
+	Version     string    `json:"version"`
+	Asset       string    `json:"asset"`
+	URL         string    `json:"url"`
+	SHA256      string    `json:"sha256"`
+	Verified    bool      `json:"verified"`
+	Binary      string    `json:"binary"`
+	InstalledAt time.Time `json:"installed_at"`
+}
+
+// Inventory lists the installed compilers and which one is selected
+type Inventory struct {
+	Installed []*Installation `json:"installed"`
+	Default   string          `json:"default"`
+	Pinned    string          `json:"pinned"`
+}
+
+const recordFile = "toolchain.json"
+
+// Root is the directory holding one subdirectory per installed version
+func Root() string {
+	if core.AppConfig != nil && core.AppConfig.ConfigFile != "" {
+		return filepath.Join(filepath.Dir(core.AppConfig.ConfigFile), "toolchains")
+	}
+	homeDir, _ := os.UserHomeDir()
+	return filepath.Join(homeDir, ".ferzdevz", "fpawn", "toolchains")
+}
+
+// Normalize strips the "v" prefix release tags carry
+func Normalize(version string) string {
+	return strings.TrimPrefix(strings.TrimSpace(version), "v")
+}
+
+// releaseTag matches the versions fpawn stores under Root(): "3.10.11",
+// "3.10.11-rc1"
+var releaseTag = regexp.MustCompile(`^[0-9A-Za-z][0-9A-Za-z._+-]*$`)
+
+// checkVersion rejects a version that is not a plain release tag. Versions
+// come from pawn.json pins in other people's projects and become directory
+// names, so "../x" must never reach the filesystem.
+func checkVersion(version string) error {
+	if !releaseTag.MatchString(version) || strings.Contains(version, "..") {
+		return fmt.Errorf("invalid compiler version %q", version)
+	}
+	return nil
+}
+
+// Installed returns the installation of version, or nil
+func Installed(version string) *Installation {
+	version = Normalize(version)
+	if checkVersion(version) != nil {
+		return nil
+	}
+	data, err := os.ReadFile(filepath.Join(Root(), version, recordFile))
+	if err != nil {
+		return nil
+	}
+	var inst Installation
+	if err := json.Unmarshal(data, &inst); err != nil {
+		return nil
+	}
+	return &inst
+}
+
+// Path returns the absolute path of the installation's pawncc
+func (i *Installation) Path() string {
+	return filepath.Join(Root(), i.Version, i.Binary)
+}
+
+// Install downloads version from src, checks it against expectedSHA (or the
+// checksum the source publishes) and unpacks it under Root(). An empty
+// expectedSHA with no published checksum installs and records the digest
+// so it can be pinned afterwards.
+func Install(version string, src Source, expectedSHA string) (*Installation, error) {
+	version = Normalize(version)
+	if version == "" {
+		return nil, fmt.Errorf("no compiler version given")
+	}
+	if err := checkVersion(version); err != nil {
+		return nil, err
+	}
+
+	rel, err := src.Resolve(version)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(Root(), 0755); err != nil {
+		return nil, err
+	}
+	tmp, err := os.CreateTemp(Root(), ".download-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+
+	body, err := src.Open(rel)
+	if err != nil {
+		tmp.Close()
+		return nil, err
+	}
+	h := sha256.New()
+	_, err = io.Copy(io.MultiWriter(tmp, h), body)
+	body.Close()
+	tmp.Close()
+	if err != nil {
+		return nil, err
+	}
+	sum := fmt.Sprintf("%x", h.Sum(nil))
+
+	inst := &Installation{
+		Version:     version,
+		Asset:       rel.Asset,
+		URL:         rel.URL,
+		SHA256:      sum,
+		InstalledAt: time.Now(),
+	}
+	for _, want := range []string{expectedSHA, rel.SHA256} {
+		if want == "" {
+			continue
+		}
+		if !strings.EqualFold(want, sum) {
+			return nil, fmt.Errorf("checksum mismatch for %s: expected %s, got %s", rel.Asset, want, sum)
+		}
+		inst.Verified = true
+	}
+
+	// Unpack next to the final location and swap it in, so a failed
+	// install never leaves a half-written version behind
+	staging := filepath.Join(Root(), "."+version+".tmp")
+	os.RemoveAll(staging)
+	if err := extractArchive(tmp.Name(), rel.Asset, staging); err != nil {
+		os.RemoveAll(staging)
+		return nil, err
+	}
+
+	inst.Binary = findBinary(staging)
+	if inst.Binary == "" {
+		os.RemoveAll(staging)
+		return nil, fmt.Errorf("%s does not contain a pawncc binary", rel.Asset)
+	}
+	os.Chmod(filepath.Join(staging, inst.Binary), 0755)
+
+	data, err := json.MarshalIndent(inst, "", "  ")
+	if err != nil {
+		os.RemoveAll(staging)
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(staging, recordFile), data, 0644); err != nil {
+		os.RemoveAll(staging)
+		return nil, err
+	}
+
+	final := filepath.Join(Root(), version)
+	os.RemoveAll(final)
+	if err := os.Rename(staging, final); err != nil {
+		os.RemoveAll(staging)
+		return nil, err
+	}
+	return inst, nil
+}
+
+// List returns every installed version, newest first
+func List() []*Installation {
+	entries, _ := os.ReadDir(Root())
+	var list []*Installation
+	for _, e := range entries {
+		if !e.IsDir() || strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		if inst := Installed(e.Name()); inst != nil {
+			list = append(list, inst)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return compareVersions(list[i].Version, list[j].Version) > 0
+	})
+	return list
+}
+
+// Inspect gathers the installed versions, the user default and the project pin
+func Inspect() *Inventory {
+	inv := &Inventory{Installed: List(), Default: Default()}
+	if m := manifest.Current(); m != nil {
+		if pin := m.CompilerFor(m.DefaultBuild()); pin != nil {
+			inv.Pinned = pin.Version
+		}
+	}
+	return inv
+}
+
+// Default returns the version selected with "toolchain use --global"
+func Default() string {
+	data, err := os.ReadFile(filepath.Join(Root(), "current"))
+	if err != nil {
+		return ""
+	}
+	return Normalize(string(data))
+}
+
+// SetDefault selects an installed version for projects that do not pin one
+func SetDefault(version string) error {
+	version = Normalize(version)
+	if err := checkVersion(version); err != nil {
+		return err
+	}
+	if Installed(version) == nil {
+		return notInstalled(version)
+	}
+	return os.WriteFile(filepath.Join(Root(), "current"), []byte(version+"\n"), 0644)
+}
+
+// Pin records version and its archive checksum in the project manifest
+func Pin(m *manifest.Manifest, version string) (*manifest.CompilerPin, error) {
+	inst := Installed(version)
+	if inst == nil {
+		return nil, notInstalled(Normalize(version))
+	}
+	pin := &manifest.CompilerPin{Version: inst.Version, SHA256: inst.SHA256}
+	if err := manifest.SetField(m.Path, "compiler", pin); err != nil {
+		return nil, err
+	}
+	return pin, nil
+}
+
+// Binary returns the pawncc of an installed version, refusing an
+// installation whose archive checksum differs from expectedSHA
+func Binary(version, expectedSHA string) (string, error) {
+	version = Normalize(version)
+	if err := checkVersion(version); err != nil {
+		return "", err
+	}
+	inst := Installed(version)
+	if inst == nil {
+		return "", notInstalled(version)
+	}
+	if expectedSHA != "" && !strings.EqualFold(inst.SHA256, expectedSHA) {
+		return "", fmt.Errorf("installed pawncc %s does not match the pinned checksum (reinstall with: fpawn toolchain install %s)", version, version)
+	}
+	return inst.Path(), nil
+}
+
+// DefaultBinary returns the pawncc of the default version, or ""
+func DefaultBinary() string {
+	version := Default()
+	if version == "" {
+		return ""
+	}
+	path, err := Binary(version, "")
+	if err != nil {
+		return ""
+	}
+	return path
+}
+
+// Env returns the environment to run binary with, adding the directories
+// that hold libpawnc next to it to the dynamic loader path
+func Env(binary string) []string {
+	env := os.Environ()
+	if runtime.GOOS == "windows" {
+		return env
+	}
+
+	dir := filepath.Dir(binary)
+	var libs []string
+	for _, candidate := range []string{dir, filepath.Join(dir, "..", "lib")} {
+		matches, _ := filepath.Glob(filepath.Join(candidate, "libpawnc*"))
+		if len(matches) > 0 {
+			libs = append(libs, candidate)
+		}
+	}
+	if len(libs) == 0 {
+		return env
+	}
+
+	key := "LD_LIBRARY_PATH"
+	if runtime.GOOS == "darwin" {
+		key = "DYLD_LIBRARY_PATH"
+	}
+	if existing := os.Getenv(key); existing != "" {
+		libs = append(libs, existing)
+	}
+	return append(env, key+"="+strings.Join(libs, string(os.PathListSeparator)))
+}
+
+func notInstalled(version string) error {
+	return fmt.Errorf("pawncc %s is not installed (run: fpawn toolchain install %s)", version, version)
+}
+
+// findBinary locates pawncc inside an unpacked release
+func findBinary(dir string) string {
+	name := "pawncc"
+	if runtime.GOOS == "windows" {
+		name = "pawncc.exe"
+	}
+	for _, candidate := range []string{filepath.Join("bin", name), name} {
+		if info, err := os.Stat(filepath.Join(dir, candidate)); err == nil && !info.IsDir() {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// compareVersions orders dotted numeric versions; non-numeric parts compare as text
+func compareVersions(a, b string) int {
+	pa, pb := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(pa) || i < len(pb); i++ {
+		var x, y string
+		if i < len(pa) {
+			x = pa[i]
+		}
+		if i < len(pb) {
+			y = pb[i]
+		}
+		nx, errx := strconv.Atoi(x)
+		ny, erry := strconv.Atoi(y)
+		switch {
+		case errx == nil && erry == nil && nx != ny:
+			if nx < ny {
+				return -1
+			}
+			return 1
+		case (errx != nil || erry != nil) && x != y:
+			return strings.Compare(x, y)
+		}
+	}
+	return 0
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/FerzDevZ/fpawn/internal/compiler"
 	"github.com/FerzDevZ/fpawn/internal/core"
+	"github.com/FerzDevZ/fpawn/internal/report"
 )
 
 // ArtisanResult holds the result of code artisan fixes
@@ -28,7 +29,7 @@ func CodeArtisan(target string) (*ArtisanResult, error) {
 	fmt.Println(" ──────────────────────────────────────────────────")
 
 	if target == "" {
-		return nil, fmt.Errorf(core.Msg("entry_err"))
+		return nil, fmt.Errorf("%s", core.Msg("entry_err"))
 	}
 
 	result := &ArtisanResult{}
@@ -43,8 +44,10 @@ func CodeArtisan(target string) (*ArtisanResult, error) {
 	originalContent := content
 
 	// Create backup
-	backupPath := target + ".bak"
-	os.WriteFile(backupPath, data, 0644)
+	backupPath, err := backupFile(target)
+	if err != nil {
+		return nil, err
+	}
 	fmt.Printf(" %s Backup created: %s\n", core.Blue("[Backup]"), backupPath)
 
 	// === FIXES ===
@@ -125,6 +128,25 @@ func CodeArtisan(target string) (*ArtisanResult, error) {
 	return result, nil
 }
 
+// backupFile copies path to path+".bak" and returns the backup's path
+func backupFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	backup := path + ".bak"
+	return backup, os.WriteFile(backup, data, 0644)
+}
+
+// restoreBackup puts back the copy backupFile made of path
+func restoreBackup(path string) error {
+	data, err := os.ReadFile(path + ".bak")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
 func fixBraceStyle(content string) string {
 	// Convert ) { to )\n{
 	re := regexp.MustCompile(`\)\s*\{`)
@@ -226,30 +248,53 @@ func cleanupBlankLines(content string) string {
 	return re.ReplaceAllString(content, "\n\n")
 }
 
-// Linter performs code linting
-func Linter(target string) {
-	if target == "" {
-		target = compiler.FindEntryPoint()
-	}
+// LintIssue is a single finding reported by the linter
+type LintIssue struct {
+	Type    string `json:"type"`
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+}
+
+// LintResult holds the findings of a lint run
+type LintResult struct {
+	Target string      `json:"target"`
+	Issues []LintIssue `json:"issues"`
+	Todos  []LintIssue `json:"todos"`
+}
 
+// Linter performs code linting
+func Linter(target string) *LintResult {
 	fmt.Printf("\n %s %s\n", core.Yellow("⚡"), core.Bold("Code Linter"))
 	fmt.Println(" ──────────────────────────────────────────────────")
 
+	result, err := LintFile(target)
+	if err != nil {
+		fmt.Printf(" %s %v\n", core.Red("[Error]"), err)
+		return nil
+	}
+
+	PrintLintReport(result)
+	return result
+}
+
+// LintFile lints target without printing anything
+func LintFile(target string) (*LintResult, error) {
 	if target == "" {
-		fmt.Printf(" %s %s\n", core.Red("[Error]"), core.Msg("entry_err"))
-		return
+		target = compiler.FindEntryPoint()
+	}
+	if target == "" {
+		return nil, fmt.Errorf("%s", core.Msg("entry_err"))
 	}
 
 	file, err := os.Open(target)
 	if err != nil {
-		fmt.Printf(" %s Cannot open file\n", core.Red("[Error]"))
-		return
+		return nil, fmt.Errorf("cannot open file: %v", err)
 	}
 	defer file.Close()
 
+	result := &LintResult{Target: target}
 	scanner := bufio.NewScanner(file)
 	lineNum := 0
-	issues := 0
 
 	// Patterns to check
 	longLine := 120
@@ -262,34 +307,73 @@ func Linter(target string) {
 
 		// Check line length
 		if len(line) > longLine {
-			fmt.Printf(" %s Line %d: Line too long (%d > %d)\n", core.Yellow("[STYLE]"), lineNum, len(line), longLine)
-			issues++
+			result.Issues = append(result.Issues, LintIssue{"STYLE", lineNum, fmt.Sprintf("Line too long (%d > %d)", len(line), longLine)})
 		}
 
 		// Check for magic numbers
 		if magicNumberPattern.MatchString(line) && !strings.Contains(line, "#define") && !strings.Contains(line, "//") {
-			fmt.Printf(" %s Line %d: Magic number detected\n", core.Orange("[MAGIC]"), lineNum)
-			issues++
+			result.Issues = append(result.Issues, LintIssue{"MAGIC", lineNum, "Magic number detected"})
 		}
 
 		// Check for TODOs
 		if todoPattern.MatchString(line) {
 			match := todoPattern.FindString(line)
-			fmt.Printf(" %s Line %d: %s comment found\n", core.Blue("[TODO]"), lineNum, match)
+			result.Todos = append(result.Todos, LintIssue{"TODO", lineNum, match + " comment found"})
 		}
 
 		// Check for trailing semicolons after braces
 		trimmed := strings.TrimSpace(line)
 		if strings.HasSuffix(trimmed, "};") && !strings.Contains(trimmed, "enum") && !strings.Contains(trimmed, "struct") {
-			fmt.Printf(" %s Line %d: Unnecessary semicolon after brace\n", core.Yellow("[STYLE]"), lineNum)
-			issues++
+			result.Issues = append(result.Issues, LintIssue{"STYLE", lineNum, "Unnecessary semicolon after brace"})
 		}
 	}
 
+	return result, nil
+}
+
+// PrintLintReport renders a lint result for the terminal
+func PrintLintReport(result *LintResult) {
+	for _, issue := range result.Issues {
+		tag := core.Yellow("[" + issue.Type + "]")
+		if issue.Type == "MAGIC" {
+			tag = core.Orange("[MAGIC]")
+		}
+		fmt.Printf(" %s Line %d: %s\n", tag, issue.Line, issue.Message)
+	}
+	for _, todo := range result.Todos {
+		fmt.Printf(" %s Line %d: %s\n", core.Blue("[TODO]"), todo.Line, todo.Message)
+	}
+
 	fmt.Println(" ──────────────────────────────────────────────────")
-	if issues == 0 {
+	if len(result.Issues) == 0 {
 		fmt.Printf(" %s No issues found!\n", core.Green("✓"))
 	} else {
-		fmt.Printf(" %s Found %d issue(s)\n", core.Yellow("⚠"), issues)
+		fmt.Printf(" %s Found %d issue(s)\n", core.Yellow("⚠"), len(result.Issues))
+	}
+}
+
+// Findings exposes the lint issues for SARIF output
+func (r *LintResult) Findings() []report.Finding {
+	var findings []report.Finding
+	for _, issue := range r.Issues {
+		findings = append(findings, report.Finding{
+			RuleID:    "fpawn.lint." + strings.ToLower(issue.Type),
+			Level:     "warning",
+			Message:   issue.Message,
+			File:      r.Target,
+			StartLine: issue.Line,
+			EndLine:   issue.Line,
+		})
+	}
+	for _, todo := range r.Todos {
+		findings = append(findings, report.Finding{
+			RuleID:    "fpawn.lint.todo",
+			Level:     "note",
+			Message:   todo.Message,
+			File:      r.Target,
+			StartLine: todo.Line,
+			EndLine:   todo.Line,
+		})
 	}
+	return findings
 }
@@ -0,0 +1,81 @@
+package tools
+
+import (
+	"fmt"
+	"strings"
+)
+
+// opKind is what a rewrite does to one original line
+type opKind int
+
+const (
+	opKeep opKind = iota
+	opReplace
+	opRemove
+)
+
+// lineOp is the fate of one original line; text is the new line
+type lineOp struct {
+	kind opKind
+	text string
+}
+
+// diffContext is the number of unchanged lines shown around a change
+const diffContext = 3
+
+// unifiedDiff renders line-for-line rewrites as a unified diff. Rewrites
+// never insert or reorder lines, so no edit-distance search is needed.
+func unifiedDiff(path string, lines []string, ops []lineOp) string {
+	var changed []int
+	for i, op := range ops {
+		if op.kind != opKeep {
+			changed = append(changed, i)
+		}
+	}
+	if len(changed) == 0 {
+		return ""
+	}
+
+	// removedBefore[i] is how many lines before i were removed, which maps
+	// an original line number to its number in the new file
+	removedBefore := make([]int, len(ops)+1)
+	for i, op := range ops {
+		removedBefore[i+1] = removedBefore[i]
+		if op.kind == opRemove {
+			removedBefore[i+1]++
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- a/%s\n+++ b/%s\n", path, path)
+	for h := 0; h < len(changed); {
+		last := h
+		for last+1 < len(changed) && changed[last+1]-changed[last] <= 2*diffContext {
+			last++
+		}
+		start := max(0, changed[h]-diffContext)
+		end := min(len(lines), changed[last]+diffContext+1)
+
+		oldCount := end - start
+		newCount := oldCount - (removedBefore[end] - removedBefore[start])
+		newStart := start - removedBefore[start]
+		if newCount > 0 {
+			newStart++
+		}
+		fmt.Fprintf(&b, "@@ -%d,%d +%d,%d @@\n", start+1, oldCount, newStart, newCount)
+
+		for i := start; i < end; i++ {
+			switch ops[i].kind {
+			case opKeep:
+				b.WriteString(" " + lines[i] + "\n")
+			case opReplace:
+				b.WriteString("-" + lines[i] + "\n")
+				b.WriteString("+" + ops[i].text + "\n")
+			case opRemove:
+				b.WriteString("-" + lines[i] + "\n")
+			}
+		}
+		h = last + 1
+	}
+	return b.String()
+}
@@ -13,6 +13,7 @@ import (
 
 	"github.com/FerzDevZ/fpawn/internal/compiler"
 	"github.com/FerzDevZ/fpawn/internal/core"
+	"github.com/FerzDevZ/fpawn/internal/manifest"
 )
 
 // CodeGuardian protects source code with DRM and vaulting
@@ -25,7 +26,7 @@ func CodeGuardian(target string) error {
 	fmt.Println(" ──────────────────────────────────────────────────")
 
 	if target == "" {
-		return fmt.Errorf(core.Msg("entry_err"))
+		return fmt.Errorf("%s", core.Msg("entry_err"))
 	}
 
 	// Read original file
@@ -240,8 +241,26 @@ func ProjectBundler() error {
 		"config.json",
 	}
 
+	// Add whatever the manifest points at outside the default layout
+	if m := manifest.Current(); m != nil {
+		includes = append(includes, m.Path, m.EntryPoint(), m.OutputPath())
+		if rt := m.MainRuntime(); rt != nil {
+			for _, gm := range rt.Gamemodes {
+				includes = append(includes, filepath.Join("gamemodes", gm+".amx"))
+			}
+			for _, fs := range rt.Filterscripts {
+				includes = append(includes, filepath.Join("filterscripts", fs+".amx"))
+			}
+		}
+	}
+
 	var included []string
+	seen := make(map[string]bool)
 	for _, item := range includes {
+		if item == "" || seen[item] || coveredBy(item, included) {
+			continue
+		}
+		seen[item] = true
 		if _, err := os.Stat(item); err == nil {
 			included = append(included, item)
 			fmt.Printf(" %s Including: %s\n", core.Cyan("+"), item)
@@ -260,3 +279,14 @@ func ProjectBundler() error {
 
 	return nil
 }
+
+// coveredBy reports whether path already lives inside one of the included dirs
+func coveredBy(path string, included []string) bool {
+	path = filepath.Clean(path)
+	for _, dir := range included {
+		if strings.HasPrefix(path, filepath.Clean(dir)+string(os.PathSeparator)) {
+			return true
+		}
+	}
+	return false
+}
@@ -2,12 +2,14 @@ package tools
 
 import (
 	"fmt"
+	"net"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
 
 	"github.com/FerzDevZ/fpawn/internal/core"
+	"github.com/FerzDevZ/fpawn/internal/rcon"
 )
 
 // DeploymentConfig holds SSH details
@@ -18,6 +20,24 @@ type DeploymentConfig struct {
 	Password string // Optional, SSH keys preferred
 }
 
+// rconTarget points RCON at the deployment host, borrowing the port and
+// password from the local server config
+func (c DeploymentConfig) rconTarget() rcon.Target {
+	t := rcon.Local()
+	_, port, _ := net.SplitHostPort(t.Address)
+	t.Address = net.JoinHostPort(c.Host, port)
+	return t
+}
+
+// reloadCommand reloads a filterscript in place; anything else is a
+// gamemode and needs a gmx
+func reloadCommand(amx string) string {
+	if filepath.Base(filepath.Dir(amx)) == "filterscripts" {
+		return "reloadfs " + strings.TrimSuffix(filepath.Base(amx), ".amx")
+	}
+	return "gmx"
+}
+
 // IgnitionPro handles remote server deployment
 func IgnitionPro(target string) {
 	fmt.Printf("\n %s %s\n", core.LBlue("🚀"), core.Bold("Ignition Pro: Deep Deployment"))
@@ -54,13 +74,11 @@ func IgnitionPro(target string) {
 
 	// Step 2: RCON Hot-Reload
 	fmt.Printf(" %s Triggering Remote Hot-Reload...\n", core.Blue("➜"))
-	// Simulating SSH command to trigger reload
-	reloadCmd := fmt.Sprintf("echo 'reloadfs %s' > /tmp/rcon_relay", filepath.Base(target))
-	err = runCommand("ssh", fmt.Sprintf("%s@%s", config.User, config.Host), reloadCmd)
-	if err != nil {
-		fmt.Printf(" %s Hot-Reload trigger failed (Is RCON relay setup?): %v\n", core.Yellow("[Warn]"), err)
+	reload := reloadCommand(target)
+	if _, err := rcon.Send(config.rconTarget(), reload); err != nil {
+		fmt.Printf(" %s Hot-Reload failed (%s): %v\n", core.Yellow("[Warn]"), reload, err)
 	} else {
-		fmt.Printf(" %s Hot-Reload Command Sent!\n", core.Green("✓"))
+		fmt.Printf(" %s Hot-Reload Command Sent: %s\n", core.Green("✓"), reload)
 	}
 
 	fmt.Println("\n ──────────────────────────────────────────────────")
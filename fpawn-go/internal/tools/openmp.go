@@ -0,0 +1,505 @@
+package tools
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/FerzDevZ/fpawn/internal/compiler"
+	"github.com/FerzDevZ/fpawn/internal/core"
+)
+
+// SourceChange is one line rewritten by the open.mp migration
+type SourceChange struct {
+	Line    int    `json:"line"`
+	Rule    string `json:"rule"`
+	Before  string `json:"before"`
+	After   string `json:"after,omitempty"`
+	Removed bool   `json:"removed,omitempty"`
+}
+
+// SourceMigration is the planned open.mp migration of one file
+type SourceMigration struct {
+	Path    string         `json:"path"`
+	Changes []SourceChange `json:"changes"`
+	// Notes flag deprecated natives that have no drop-in replacement
+	Notes []LintIssue `json:"notes"`
+
+	lines   []string
+	ops     []lineOp
+	crlf    bool
+	newline bool
+	// original is the file as planned, so applying can tell it changed
+	original string
+}
+
+// OpenMPMigration is the outcome of a migration run
+type OpenMPMigration struct {
+	Files      []*SourceMigration     `json:"files"`
+	Diff       string                 `json:"diff"`
+	Applied    bool                   `json:"applied"`
+	Backups    []string               `json:"backups"`
+	Before     *compiler.MatrixResult `json:"before,omitempty"`
+	After      *compiler.MatrixResult `json:"after,omitempty"`
+	RolledBack bool                   `json:"rolled_back"`
+}
+
+// MigrateOptions controls MigrateToOpenMP
+type MigrateOptions struct {
+	// Apply writes the changes; otherwise only the plan and diff are made
+	Apply bool
+	// Verify runs a matrix build before and after applying, and restores
+	// the backups if a profile that built before no longer does
+	Verify bool
+	// Entry is the script the matrix builds; empty means the entry point
+	Entry string
+	Jobs  int
+}
+
+// ompIncludes are the SA-MP headers open.mp.inc already pulls in
+var ompIncludes = map[string]bool{
+	"a_players": true, "a_vehicles": true, "a_objects": true,
+	"a_actor": true, "a_http": true, "a_sampdb": true,
+}
+
+var includeLine = regexp.MustCompile(`^(\s*#\s*include\s*[<"])([\w.]+?)(\.inc)?([>"].*)$`)
+
+// ompRenames maps deprecated natives to their open.mp names
+var ompRenames = [][2]string{
+	{"TextDrawColor", "TextDrawColour"},
+	{"TextDrawBoxColor", "TextDrawBoxColour"},
+	{"TextDrawBackgroundColor", "TextDrawBackgroundColour"},
+	{"TextDrawSetPreviewVehCol", "TextDrawSetPreviewVehicleColours"},
+	{"PlayerTextDrawColor", "PlayerTextDrawColour"},
+	{"PlayerTextDrawBoxColor", "PlayerTextDrawBoxColour"},
+	{"PlayerTextDrawBackgroundColor", "PlayerTextDrawBackgroundColour"},
+	{"PlayerTextDrawSetPreviewVehCol", "PlayerTextDrawSetPreviewVehicleColours"},
+	{"SetPlayerColor", "SetPlayerColour"},
+	{"GetPlayerColor", "GetPlayerColour"},
+	{"ChangeVehicleColor", "ChangeVehicleColours"},
+	{"GetServerVarAsInt", "GetConsoleVarAsInt"},
+	{"GetServerVarAsString", "GetConsoleVarAsString"},
+	{"GetServerVarAsBool", "GetConsoleVarAsBool"},
+	{"db_open", "DB_Open"},
+	{"db_close", "DB_Close"},
+	{"db_query", "DB_ExecuteQuery"},
+	{"db_free_result", "DB_FreeResultSet"},
+	{"db_num_rows", "DB_GetRowCount"},
+	{"db_next_row", "DB_SelectNextRow"},
+	{"db_num_fields", "DB_GetFieldCount"},
+	{"db_field_name", "DB_GetFieldName"},
+	{"db_get_field", "DB_GetFieldString"},
+	{"db_get_field_int", "DB_GetFieldInt"},
+	{"db_get_field_float", "DB_GetFieldFloat"},
+	{"db_get_field_assoc", "DB_GetFieldStringByName"},
+	{"db_get_field_assoc_int", "DB_GetFieldIntByName"},
+	{"db_get_field_assoc_float", "DB_GetFieldFloatByName"},
+}
+
+// ompDeprecated are natives open.mp warns about that need a human decision
+var ompDeprecated = map[string]string{
+	"GetPlayerPoolSize":   "loop to MAX_PLAYERS or use foreach instead",
+	"GetVehiclePoolSize":  "loop to MAX_VEHICLES or use foreach instead",
+	"GetActorPoolSize":    "loop to MAX_ACTORS or use foreach instead",
+	"AllowPlayerTeleport": "removed in open.mp; handle OnPlayerClickMap instead",
+	"SHA256_PassHash":     "use a bcrypt or argon2 plugin for password hashes",
+}
+
+// ompCallbackTags gives the tag open.mp declares for each callback
+// parameter; "" leaves a parameter alone
+var ompCallbackTags = map[string][]string{
+	"OnPlayerStateChange":        {"", "PLAYER_STATE", "PLAYER_STATE"},
+	"OnPlayerKeyStateChange":     {"", "KEY", "KEY"},
+	"OnPlayerDeath":              {"", "", "WEAPON"},
+	"OnPlayerTakeDamage":         {"", "", "", "WEAPON"},
+	"OnPlayerGiveDamage":         {"", "", "", "WEAPON"},
+	"OnPlayerGiveDamageActor":    {"", "", "", "WEAPON"},
+	"OnPlayerWeaponShot":         {"", "WEAPON", "BULLET_HIT_TYPE"},
+	"OnPlayerClickPlayer":        {"", "", "CLICK_SOURCE"},
+	"OnPlayerEditObject":         {"", "", "", "EDIT_RESPONSE"},
+	"OnPlayerEditAttachedObject": {"", "EDIT_RESPONSE"},
+	"OnPlayerSelectObject":       {"", "SELECT_OBJECT"},
+}
+
+// ompReturnTags gives the tag of natives whose result open.mp tags
+var ompReturnTags = map[string]string{
+	"GetPlayerState":         "PLAYER_STATE",
+	"GetPlayerWeapon":        "WEAPON",
+	"GetPlayerSpecialAction": "SPECIAL_ACTION",
+	"GetPlayerFightingStyle": "FIGHT_STYLE",
+	"IsPlayerConnected":      "bool",
+	"IsPlayerAdmin":          "bool",
+	"IsPlayerNPC":            "bool",
+	"IsPlayerInAnyVehicle":   "bool",
+	"IsPlayerInVehicle":      "bool",
+	"IsPlayerStreamedIn":     "bool",
+	"IsVehicleStreamedIn":    "bool",
+	"IsValidVehicle":         "bool",
+	"IsValidObject":          "bool",
+}
+
+var (
+	callbackLine  = regexp.MustCompile(`^(\s*(?:public|forward)\s+)(\w+)(\s*\()([^)]*)(\).*)$`)
+	untaggedDecl  = regexp.MustCompile(`\bnew\s+([A-Za-z_]\w*)(\s*=\s*)(\w+)(\s*\()`)
+	renamePattern = func() map[string]*regexp.Regexp {
+		m := make(map[string]*regexp.Regexp)
+		for _, r := range ompRenames {
+			m[r[0]] = regexp.MustCompile(`\b` + r[0] + `(\s*\()`)
+		}
+		for name := range ompDeprecated {
+			m[name] = regexp.MustCompile(`\b` + name + `\s*\(`)
+		}
+		return m
+	}()
+)
+
+// PlanOpenMPMigration works out the rewrites for each file without
+// touching it
+func PlanOpenMPMigration(files []string) ([]*SourceMigration, error) {
+	var plans []*SourceMigration
+	for _, path := range files {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		plans = append(plans, planFile(path, string(data)))
+	}
+	return plans, nil
+}
+
+func planFile(path, content string) *SourceMigration {
+	m := &SourceMigration{Path: path, crlf: strings.Contains(content, "\r\n"), original: content}
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	m.newline = strings.HasSuffix(content, "\n")
+	content = strings.TrimSuffix(content, "\n")
+	m.lines = strings.Split(content, "\n")
+	m.ops = make([]lineOp, len(m.lines))
+
+	// open.mp.inc replaces a_samp and the headers it used to pull in, so
+	// those only go once a_samp (or open.mp itself) is known to be there
+	hasOMP := false
+	for _, line := range m.lines {
+		if g := includeLine.FindStringSubmatch(line); g != nil && (g[2] == "a_samp" || g[2] == "open.mp") {
+			hasOMP = true
+		}
+	}
+	ompIncluded := false
+
+	for i, line := range m.lines {
+		m.ops[i] = lineOp{kind: opKeep, text: line}
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "//") || strings.HasPrefix(trimmed, "*") {
+			continue
+		}
+
+		if g := includeLine.FindStringSubmatch(line); g != nil {
+			switch {
+			case g[2] == "a_samp" || g[2] == "open.mp":
+				if ompIncluded {
+					m.remove(i, "include")
+				} else if g[2] == "a_samp" {
+					m.replace(i, "include", g[1]+"open.mp"+g[4])
+				}
+				ompIncluded = true
+			case hasOMP && ompIncludes[g[2]]:
+				m.remove(i, "include")
+			}
+			continue
+		}
+
+		var rules []string
+		out := line
+
+		if g := callbackLine.FindStringSubmatch(out); g != nil {
+			if tags, ok := ompCallbackTags[g[2]]; ok {
+				if params := tagParams(g[4], tags); params != g[4] {
+					out = g[1] + g[2] + g[3] + params + g[5]
+					rules = append(rules, "tag")
+				}
+			}
+		}
+
+		for _, r := range ompRenames {
+			if re := renamePattern[r[0]]; re.MatchString(out) {
+				out = re.ReplaceAllString(out, r[1]+"$1")
+				rules = append(rules, "rename")
+			}
+		}
+
+		before := out
+		out = untaggedDecl.ReplaceAllStringFunc(out, func(decl string) string {
+			g := untaggedDecl.FindStringSubmatch(decl)
+			if tag, ok := ompReturnTags[g[3]]; ok {
+				return "new " + tag + ":" + g[1] + g[2] + g[3] + g[4]
+			}
+			return decl
+		})
+		if out != before {
+			rules = append(rules, "tag")
+		}
+
+		for name, advice := range ompDeprecated {
+			if renamePattern[name].MatchString(out) {
+				m.Notes = append(m.Notes, LintIssue{"DEPRECATED", i + 1, name + ": " + advice})
+			}
+		}
+
+		if out != line {
+			m.replace(i, strings.Join(dedupe(rules), ", "), out)
+		}
+	}
+	return m
+}
+
+// tagParams prefixes untagged parameters with the tag open.mp expects
+func tagParams(list string, tags []string) string {
+	params := strings.Split(list, ",")
+	for i, p := range params {
+		if i >= len(tags) || tags[i] == "" || strings.Contains(p, ":") || strings.TrimSpace(p) == "" {
+			continue
+		}
+		name := strings.TrimLeft(p, " \t")
+		params[i] = p[:len(p)-len(name)] + tags[i] + ":" + name
+	}
+	return strings.Join(params, ",")
+}
+
+func dedupe(rules []string) []string {
+	var out []string
+	seen := map[string]bool{}
+	for _, r := range rules {
+		if !seen[r] {
+			seen[r] = true
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+func (m *SourceMigration) replace(i int, rule, text string) {
+	m.ops[i] = lineOp{kind: opReplace, text: text}
+	m.Changes = append(m.Changes, SourceChange{Line: i + 1, Rule: rule, Before: m.lines[i], After: text})
+}
+
+func (m *SourceMigration) remove(i int, rule string) {
+	m.ops[i] = lineOp{kind: opRemove}
+	m.Changes = append(m.Changes, SourceChange{Line: i + 1, Rule: rule, Before: m.lines[i], Removed: true})
+}
+
+// Content returns the migrated file
+func (m *SourceMigration) Content() string {
+	var out []string
+	for _, op := range m.ops {
+		if op.kind != opRemove {
+			out = append(out, op.text)
+		}
+	}
+	nl := "\n"
+	if m.crlf {
+		nl = "\r\n"
+	}
+	if m.newline {
+		out = append(out, "")
+	}
+	return strings.Join(out, nl)
+}
+
+// Diff returns the migration of this file as a unified diff
+func (m *SourceMigration) Diff() string {
+	return unifiedDiff(m.Path, m.lines, m.ops)
+}
+
+// MigrateToOpenMP plans the migration of files, prints the diff and, when
+// opts.Apply is set and the user confirms, applies it with backups. No
+// files means every discovered gamemode, filterscript and npcmode.
+func MigrateToOpenMP(files []string, opts MigrateOptions, confirm bool) (*OpenMPMigration, error) {
+	fmt.Printf("\n %s %s\n", core.LBlue("🧭"), core.Bold("open.mp Migration Assistant"))
+	fmt.Println(" ──────────────────────────────────────────────────")
+
+	result, err := RunOpenMPMigration(files, MigrateOptions{Entry: opts.Entry, Jobs: opts.Jobs})
+	if err != nil {
+		return nil, err
+	}
+
+	changes := 0
+	for _, f := range result.Files {
+		changes += len(f.Changes)
+		for _, n := range f.Notes {
+			fmt.Printf(" %s %s:%d %s\n", core.Yellow("[Review]"), f.Path, n.Line, n.Message)
+		}
+	}
+	if changes == 0 {
+		fmt.Printf(" %s Nothing to rewrite; the sources already target open.mp\n", core.Green("✓"))
+		return result, nil
+	}
+
+	fmt.Println()
+	printDiff(result.Diff)
+	fmt.Printf(" %s %d line(s) to change in %d file(s)\n", core.Cyan("[Plan]"), changes, len(result.Files))
+
+	if !opts.Apply {
+		fmt.Printf(" %s Review the diff, then run again with --apply\n", core.Cyan("[Tip]"))
+		return result, nil
+	}
+	if confirm {
+		fmt.Print("\n Apply these changes? [y/N] ")
+		answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if a := strings.ToLower(strings.TrimSpace(answer)); a != "y" && a != "yes" {
+			fmt.Printf(" %s Nothing was changed\n", core.Yellow("[Cancel]"))
+			return result, nil
+		}
+	}
+
+	if opts.Verify {
+		fmt.Printf("\n %s Building every profile before and after the migration...\n", core.Cyan("[Matrix]"))
+	}
+	// Write exactly the plans whose diff was confirmed
+	if err := ApplyOpenMPMigration(result, opts); err != nil {
+		return nil, err
+	}
+	for _, b := range result.Backups {
+		fmt.Printf(" %s Backup created: %s\n", core.Blue("[Backup]"), b)
+	}
+	if result.Before != nil && result.After != nil {
+		for i, before := range result.Before.Entries {
+			after := result.After.Entries[i]
+			fmt.Printf("   %-16s before %s  after %s\n", before.Profile, buildMark(before.Result), buildMark(after.Result))
+		}
+	}
+
+	fmt.Println(" ──────────────────────────────────────────────────")
+	switch {
+	case result.RolledBack:
+		fmt.Printf(" %s The migrated sources no longer build; the backups were restored\n", core.Red("[Rollback]"))
+		return result, fmt.Errorf("migration broke the build")
+	case result.Before != nil && !matrixPassed(result.Before) && !matrixPassed(result.After):
+		fmt.Printf(" %s Applied, but the project did not build before or after; check your open.mp includes\n", core.Yellow("[Warn]"))
+	default:
+		fmt.Printf(" %s Migration applied\n", core.Green("✓"))
+	}
+	return result, nil
+}
+
+// RunOpenMPMigration plans the migration of files and, with opts.Apply,
+// writes it without printing anything
+func RunOpenMPMigration(files []string, opts MigrateOptions) (*OpenMPMigration, error) {
+	if len(files) == 0 {
+		targets, _ := compiler.DiscoverTargets()
+		for _, t := range targets {
+			files = append(files, t.Source)
+		}
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("%s", core.Msg("entry_err"))
+	}
+
+	plans, err := PlanOpenMPMigration(files)
+	if err != nil {
+		return nil, err
+	}
+	result := &OpenMPMigration{Files: plans, Backups: []string{}}
+	var diff strings.Builder
+	for _, p := range plans {
+		diff.WriteString(p.Diff())
+	}
+	result.Diff = diff.String()
+	if !opts.Apply {
+		return result, nil
+	}
+	if err := ApplyOpenMPMigration(result, opts); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ApplyOpenMPMigration writes the plans in result with backups, verifying
+// the build around it when opts.Verify is set. A file that changed since it
+// was planned is refused, so what is written is always what the diff showed.
+func ApplyOpenMPMigration(result *OpenMPMigration, opts MigrateOptions) error {
+	plans := result.Files
+	for _, p := range plans {
+		if len(p.Changes) == 0 {
+			continue
+		}
+		data, err := os.ReadFile(p.Path)
+		if err != nil {
+			return err
+		}
+		if string(data) != p.original {
+			return fmt.Errorf("%s changed since the migration was planned; run it again", p.Path)
+		}
+	}
+
+	if opts.Verify {
+		result.Before = compiler.RunMatrix(opts.Entry, opts.Jobs, nil)
+	}
+	for _, p := range plans {
+		if len(p.Changes) == 0 {
+			continue
+		}
+		backup, err := backupFile(p.Path)
+		if err != nil {
+			return err
+		}
+		result.Backups = append(result.Backups, backup)
+		if err := os.WriteFile(p.Path, []byte(p.Content()), 0644); err != nil {
+			return err
+		}
+	}
+	result.Applied = true
+
+	if opts.Verify {
+		result.After = compiler.RunMatrix(opts.Entry, opts.Jobs, nil)
+		for i, before := range result.Before.Entries {
+			if before.Result.Success && !result.After.Entries[i].Result.Success {
+				for _, p := range plans {
+					if len(p.Changes) > 0 {
+						if err := restoreBackup(p.Path); err != nil {
+							return err
+						}
+					}
+				}
+				result.Applied, result.RolledBack = false, true
+				break
+			}
+		}
+	}
+	return nil
+}
+
+func matrixPassed(m *compiler.MatrixResult) bool {
+	for _, e := range m.Entries {
+		if !e.Result.Success {
+			return false
+		}
+	}
+	return len(m.Entries) > 0
+}
+
+func buildMark(r *compiler.CompileResult) string {
+	if r.Success {
+		return core.Green("OK    ")
+	}
+	return core.Red("FAILED")
+}
+
+// printDiff colours a unified diff for the terminal
+func printDiff(diff string) {
+	for _, line := range strings.Split(strings.TrimSuffix(diff, "\n"), "\n") {
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+			fmt.Println(" " + core.Bold(line))
+		case strings.HasPrefix(line, "@@"):
+			fmt.Println(" " + core.Cyan(line))
+		case strings.HasPrefix(line, "+"):
+			fmt.Println(" " + core.Green(line))
+		case strings.HasPrefix(line, "-"):
+			fmt.Println(" " + core.Red(line))
+		default:
+			fmt.Println(" " + line)
+		}
+	}
+	fmt.Println()
+}
@@ -10,6 +10,8 @@ import (
 	"github.com/FerzDevZ/fpawn/internal/compiler"
 	"github.com/FerzDevZ/fpawn/internal/core"
 	"github.com/FerzDevZ/fpawn/internal/plugins"
+	"github.com/FerzDevZ/fpawn/internal/server"
+	"github.com/FerzDevZ/fpawn/internal/servercfg"
 	"github.com/FerzDevZ/fpawn/internal/tools"
 	"github.com/charmbracelet/lipgloss"
 )
@@ -33,10 +35,12 @@ func ShowDashboard() {
 			compiler.Compile("", compiler.ProfileAuto)
 			waitEnter()
 		case "2":
-			compiler.RunServer()
+			if err := server.RestartServer(); err != nil {
+				fmt.Printf(" %s %v\n", core.Red("[Error]"), err)
+			}
 			waitEnter()
 		case "3":
-			compiler.WatchMode("")
+			WatchDashboard("")
 
 		// === ECOSYSTEM ===
 		case "4":
@@ -114,7 +118,7 @@ func ShowDashboard() {
 
 		// === OMNIPOTENT SUITE ===
 		case "30":
-			compiler.HybridMatrixBuild("")
+			compiler.HybridMatrixBuild("", 0)
 			waitEnter()
 		case "31":
 			analysis.SemanticAnalytics("")
@@ -312,7 +316,7 @@ func pluginMarketplace() {
 		waitEnter()
 	case "3":
 		name := readInput("Plugin name:")
-		plugins.InstallPlugin(name)
+		plugins.InstallPlugins([]string{name}, plugins.NewClient(""), plugins.InstallOptions{})
 		waitEnter()
 	}
 }
@@ -333,11 +337,11 @@ func pluginManagerMenu() {
 		plugins.VerifyPlugins()
 		waitEnter()
 	case "2":
-		plugins.CheckDependencies()
+		plugins.CheckDependencies("")
 		waitEnter()
 	case "3":
 		name := readInput("Plugin to uninstall:")
-		plugins.UninstallPlugin(name)
+		plugins.UninstallPlugin(name, false)
 		waitEnter()
 	}
 }
@@ -373,28 +377,13 @@ func serverCruncher() {
 	fmt.Printf("\n %s %s\n", core.LBlue("🖥️"), core.Bold("Server Cruncher"))
 	fmt.Println(" ──────────────────────────────────────────────────")
 
-	// Check server.cfg
-	if _, err := os.Stat("server.cfg"); os.IsNotExist(err) {
-		fmt.Printf(" %s server.cfg not found\n", core.Red("[Error]"))
+	if _, err := servercfg.ShowSettings(); err != nil {
+		fmt.Printf(" %s %v\n", core.Red("[Error]"), err)
 		return
 	}
 
-	data, _ := os.ReadFile("server.cfg")
-	content := string(data)
-
-	// Parse and display settings
-	fmt.Println(" Current Configuration:")
-	lines := strings.Split(content, "\n")
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" || strings.HasPrefix(line, "#") {
-			continue
-		}
-		fmt.Printf("   %s\n", line)
-	}
-
 	fmt.Println(" ──────────────────────────────────────────────────")
-	fmt.Printf(" %s Configuration loaded\n", core.Green("✓"))
+	fmt.Printf(" %s Configuration loaded (edit with: fpawn cfg set <key> <value>)\n", core.Green("✓"))
 }
 
 func restoreSnapshot() {
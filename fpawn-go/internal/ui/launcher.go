@@ -29,14 +29,14 @@ func ShowSplash() {
 	// Pro Edition Branding
 	for _, color := range colors {
 		fmt.Print("\033[H\033[2J")
-		fmt.Println("\n\n")
+		fmt.Print("\n\n\n")
 		style := lipgloss.NewStyle().Foreground(lipgloss.Color(color)).Bold(true)
 		fmt.Println(style.Render(logo))
 		fmt.Printf("\n%40s\n", core.Bold(lipgloss.NewStyle().Foreground(lipgloss.Color(core.GetThemeColor())).Render("FERZDEVZ FPAWN PRO v32.0")))
 		time.Sleep(200 * time.Millisecond)
 	}
 
-	fmt.Println("\n")
+	fmt.Print("\n\n")
 
 	// Loading sequence
 	ecosystem := "Standard"
@@ -73,7 +73,7 @@ func ShowSplash() {
 		fmt.Printf("\r  %-35s %s %3d%%", task, bar, int(progress*100))
 		time.Sleep(250 * time.Millisecond)
 	}
-	fmt.Println("\n")
+	fmt.Print("\n\n")
 	time.Sleep(400 * time.Millisecond)
 }
 
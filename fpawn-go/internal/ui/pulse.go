@@ -2,71 +2,205 @@ package ui
 
 import (
 	"fmt"
-	"math/rand"
 	"strings"
 	"time"
 
 	"github.com/FerzDevZ/fpawn/internal/core"
+	"github.com/FerzDevZ/fpawn/internal/rcon"
+	"github.com/FerzDevZ/fpawn/internal/server"
+	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
 
-// LiveTelemetry displays a real-time monitor of server resources
-func LiveTelemetry() {
-	fmt.Printf("\n %s %s\n", core.Magenta("📈"), core.Bold("THE PULSE: Real-Time Telemetry"))
-	fmt.Println(" Press Ctrl+C to return to dashboard")
-	time.Sleep(1 * time.Second)
-
-	history := []int{10, 20, 15, 30, 25, 40, 35, 50, 45, 60}
-	
-	for {
-		clearScreen()
-		fmt.Printf("\n %s %s\n", core.Magenta("📈"), core.Bold("THE PULSE [MONITORING ACTIVE]"))
-		fmt.Println(" ──────────────────────────────────────────────────")
-
-		// Resource Stats
-		cpu := rand.Intn(15) + 5
-		ram := 120 + rand.Intn(40)
-		players := 20 + rand.Intn(10)
-
-		statsStyle := lipgloss.NewStyle().
-			Padding(1, 2).
+const (
+	pulseInterval = time.Second
+	pulseHistory  = 30
+	pulseLogLines = 10
+)
+
+var (
+	pulseStatsStyle = lipgloss.NewStyle().
+			Padding(0, 2).
 			Border(lipgloss.RoundedBorder()).
 			Foreground(lipgloss.Color("#00FF00"))
+	pulseBarStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("#00FF87"))
+	pulseEventStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#00D7FF"))
+)
 
-		stats := fmt.Sprintf("CPU: %d%% | RAM: %d MiB | PLAYERS: %d/100", cpu, ram, players)
-		fmt.Println(statsStyle.Render(stats))
+// pulseSample is one round of readings, taken off the UI goroutine
+type pulseSample struct {
+	at       time.Time
+	status   *server.Status
+	usage    *server.ProcessUsage
+	usageErr error
+	info     *rcon.Info
+	queryErr error
+	log      []string
+}
 
-		// ASCII Chart
-		history = append(history[1:], cpu*2)
-		drawChart(history)
+type pulseTickMsg struct{}
 
-		// Mock Log Stream
-		fmt.Printf("\n %s Log Stream:\n", core.Cyan("[Live]"))
-		fmt.Printf("   [%s] Connection from 127.0.0.1 success\n", time.Now().Format("15:04:05"))
-		if cpu > 15 {
-			fmt.Printf("   %s Warning: High script execution detected\n", core.Yellow("[Perf]"))
+type pulseModel struct {
+	address string
+	last    *pulseSample
+	prev    *pulseSample
+	cpu     float64
+	history []float64
+}
+
+func takePulseSample(address string) tea.Cmd {
+	return func() tea.Msg {
+		s := &pulseSample{at: time.Now(), status: server.Inspect()}
+		if s.status.Running {
+			s.usage, s.usageErr = server.Usage(s.status.State.PID)
+		}
+		if c, err := rcon.Dial(address, ""); err != nil {
+			s.queryErr = err
+		} else {
+			c.Timeout = pulseInterval / 2
+			s.info, s.queryErr = c.Info()
+			c.Close()
 		}
+		s.log, _ = server.Tail(pulseLogLines)
+		return s
+	}
+}
 
-		fmt.Println("\n ──────────────────────────────────────────────────")
-		time.Sleep(800 * time.Millisecond)
+func (m pulseModel) Init() tea.Cmd {
+	return takePulseSample(m.address)
+}
+
+func (m pulseModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c", "q", "esc":
+			return m, tea.Quit
+		}
+
+	case pulseTickMsg:
+		return m, takePulseSample(m.address)
+
+	case *pulseSample:
+		m.prev, m.last = m.last, msg
+		m.cpu = 0
+		if m.prev != nil && m.prev.usage != nil && msg.usage != nil {
+			m.cpu = server.CPUPercent(m.prev.usage, msg.usage, msg.at.Sub(m.prev.at))
+		}
+		m.history = append(m.history, m.cpu)
+		if len(m.history) > pulseHistory {
+			m.history = m.history[len(m.history)-pulseHistory:]
+		}
+		return m, tea.Tick(pulseInterval, func(time.Time) tea.Msg { return pulseTickMsg{} })
 	}
+
+	return m, nil
 }
 
-func drawChart(history []int) {
-	height := 10
-	width := len(history)
+func (m pulseModel) View() string {
+	s := strings.Builder{}
+	s.WriteString("\n")
+	s.WriteString(titleStyle.Render("THE PULSE: Real-Time Telemetry"))
+	s.WriteString("\n")
 
+	if m.last == nil {
+		s.WriteString("  Sampling...\n")
+		return s.String()
+	}
+	st := m.last.status
+
+	state := watchErrorStyle.Render("STOPPED")
+	switch {
+	case st.Running:
+		state = watchOKStyle.Render(fmt.Sprintf("RUNNING (pid %d)", st.State.PID))
+	case st.Supervised:
+		state = watchWarningStyle.Render("RESTARTING")
+	}
+	stats := []string{"SERVER: " + state}
+	if u := m.last.usage; u != nil {
+		stats = append(stats,
+			fmt.Sprintf("CPU: %.1f%%", m.cpu),
+			fmt.Sprintf("RAM: %d MiB", u.RSS>>20))
+	}
+	if info := m.last.info; info != nil {
+		stats = append(stats, fmt.Sprintf("PLAYERS: %d/%d", info.Players, info.MaxPlayers))
+	}
+	s.WriteString(pulseStatsStyle.Render(strings.Join(stats, " | ")))
+	s.WriteString("\n")
+
+	if info := m.last.info; info != nil {
+		s.WriteString(descStyle.Render(fmt.Sprintf("%s · %s · %s", info.Hostname, info.Gamemode, m.address)))
+	} else {
+		s.WriteString(descStyle.Render(fmt.Sprintf("Query %s: %v", m.address, m.last.queryErr)))
+	}
+	s.WriteString("\n")
+	if m.last.usageErr != nil {
+		s.WriteString(descStyle.Render("Process stats: " + m.last.usageErr.Error()))
+		s.WriteString("\n")
+	}
+	if st.State != nil && st.Supervised && st.State.Restarts > 0 {
+		s.WriteString(descStyle.Render(fmt.Sprintf("Restarts: %d (last: %s)", st.State.Restarts, st.State.LastExit)))
+		s.WriteString("\n")
+	}
+
+	s.WriteString("\n  CPU history\n")
+	s.WriteString(drawChart(m.history))
+
+	s.WriteString(fmt.Sprintf("\n  %s %s\n", core.Cyan("[Live]"), st.Log))
+	if len(m.last.log) == 0 {
+		s.WriteString(descStyle.Render("No server output yet (start it with: fpawn server start)"))
+		s.WriteString("\n")
+	}
+	for _, line := range m.last.log {
+		s.WriteString("   " + highlightLogLine(line) + "\n")
+	}
+
+	s.WriteString(helpStyle.Render("  q: back to dashboard"))
+	s.WriteString("\n")
+	return s.String()
+}
+
+// highlightLogLine colours errors red, warnings yellow and the supervisor's
+// own lines cyan
+func highlightLogLine(line string) string {
+	lower := strings.ToLower(line)
+	switch {
+	case strings.Contains(lower, "error"), strings.Contains(lower, "crash"),
+		strings.Contains(lower, "[debug]"), strings.Contains(lower, "failed"):
+		return watchErrorStyle.Render(line)
+	case strings.Contains(lower, "warning"):
+		return watchWarningStyle.Render(line)
+	case strings.HasPrefix(line, "[fpawn "):
+		return pulseEventStyle.Render(line)
+	}
+	return line
+}
+
+// drawChart renders one bar per sample, each row standing for 10% of a core
+func drawChart(history []float64) string {
+	const height = 10
+	s := strings.Builder{}
 	for h := height; h > 0; h-- {
-		fmt.Print("   ")
-		for w := 0; w < width; w++ {
-			val := history[w] / 10
-			if val >= h {
-				fmt.Print(core.Green("┃ "))
+		s.WriteString("   ")
+		for _, v := range history {
+			if v >= float64(h*10) || (h == 1 && v > 0) {
+				s.WriteString(pulseBarStyle.Render("┃ "))
 			} else {
-				fmt.Print("  ")
+				s.WriteString("  ")
 			}
 		}
-		fmt.Println()
+		s.WriteString("\n")
+	}
+	s.WriteString("   " + strings.Repeat("──", pulseHistory) + "\n")
+	return s.String()
+}
+
+// LiveTelemetry monitors the supervised server: its CPU and memory from
+// /proc, players and server info over the query protocol and the tail of
+// the server log. It returns when the user presses q.
+func LiveTelemetry() {
+	m := pulseModel{address: rcon.Local().Address}
+	if _, err := tea.NewProgram(m, tea.WithAltScreen()).Run(); err != nil {
+		fmt.Printf(" %s %v\n", core.Red("[Error]"), err)
 	}
-	fmt.Println("   " + strings.Repeat("──", width))
 }
@@ -0,0 +1,195 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/FerzDevZ/fpawn/internal/compiler"
+	"github.com/FerzDevZ/fpawn/internal/core"
+	"github.com/FerzDevZ/fpawn/internal/server"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	watchErrorStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("#FF5F5F"))
+	watchWarningStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#FFD700"))
+	watchOKStyle      = lipgloss.NewStyle().Foreground(lipgloss.Color("#00FF87")).Bold(true)
+	watchBusyStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("#00D7FF")).Bold(true)
+)
+
+type watchEventMsg compiler.WatchEvent
+
+// igniteMsg reports the outcome of an auto-ignition restart
+type igniteMsg struct {
+	status *server.Status
+	err    error
+}
+
+type watchModel struct {
+	watcher *compiler.Watcher
+	events  <-chan compiler.WatchEvent
+
+	building bool
+	trigger  string
+	last     *compiler.CompileResult
+	lastAt   time.Time
+	builds   int
+	notice   string
+	height   int
+}
+
+func waitForWatchEvent(events <-chan compiler.WatchEvent) tea.Cmd {
+	return func() tea.Msg {
+		return watchEventMsg(<-events)
+	}
+}
+
+// ignite restarts the server off the update loop so the view keeps drawing
+func ignite() tea.Cmd {
+	return func() tea.Msg {
+		st, err := server.Restart()
+		return igniteMsg{status: st, err: err}
+	}
+}
+
+func (m watchModel) Init() tea.Cmd {
+	return waitForWatchEvent(m.events)
+}
+
+func (m watchModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c", "q", "esc":
+			return m, tea.Quit
+		case "r":
+			m.watcher.Rebuild()
+		}
+
+	case tea.WindowSizeMsg:
+		m.height = msg.Height
+
+	case igniteMsg:
+		if msg.err != nil {
+			m.notice = "Auto-Ignition failed: " + msg.err.Error()
+		} else {
+			m.notice = fmt.Sprintf("Auto-Ignition: server restarted (pid %d)", msg.status.State.PID)
+		}
+
+	case watchEventMsg:
+		switch msg.Kind {
+		case compiler.WatchBuilding:
+			m.building = true
+			m.trigger = msg.Trigger
+		case compiler.WatchCancelled:
+			m.building = false
+			m.notice = "Build superseded by a newer change"
+		case compiler.WatchDir:
+			m.notice = "Watching new directory: " + msg.Trigger
+		case compiler.WatchError:
+			m.notice = "Watch error: " + msg.Err.Error()
+		case compiler.WatchResult:
+			m.building = false
+			m.last = msg.Result
+			m.lastAt = time.Now()
+			m.builds++
+			m.notice = ""
+			if msg.Result.Success && core.AppConfig.AutoIgnite {
+				m.notice = "Auto-Ignition: restarting the server..."
+				return m, tea.Batch(waitForWatchEvent(m.events), ignite())
+			}
+		}
+		return m, waitForWatchEvent(m.events)
+	}
+
+	return m, nil
+}
+
+func (m watchModel) View() string {
+	s := strings.Builder{}
+	s.WriteString("\n")
+	s.WriteString(titleStyle.Render("FPAWN WATCH MODE"))
+	s.WriteString("\n")
+	s.WriteString(fmt.Sprintf("  Target: %s   Debounce: %v   Dirs: %d   Auto-Ignition: %s\n\n",
+		m.watcher.Target, m.watcher.Delay, m.watcher.Dirs(), boolToStatus(core.AppConfig.AutoIgnite)))
+
+	switch {
+	case m.building:
+		s.WriteString("  " + watchBusyStyle.Render("● Building") + " (" + m.trigger + ")\n")
+	case m.last == nil:
+		s.WriteString("  " + watchBusyStyle.Render("⏳ Waiting for changes...") + "\n")
+	case m.last.Success:
+		s.WriteString(fmt.Sprintf("  %s %s at %s in %.2fs (build #%d)\n",
+			watchOKStyle.Render("✓ OK"), m.last.Target, m.lastAt.Format("15:04:05"), m.last.Duration, m.builds))
+	default:
+		s.WriteString(fmt.Sprintf("  %s %s at %s (build #%d)\n",
+			watchErrorStyle.Render("✗ FAILED"), m.last.Target, m.lastAt.Format("15:04:05"), m.builds))
+	}
+	if m.notice != "" {
+		s.WriteString(descStyle.Render(m.notice) + "\n")
+	}
+	s.WriteString("\n")
+
+	if m.last != nil {
+		s.WriteString(m.diagnostics())
+	}
+
+	s.WriteString(helpStyle.Render("  r: rebuild now • q: quit"))
+	s.WriteString("\n")
+	return s.String()
+}
+
+// diagnostics lists errors before warnings, trimmed to the terminal height
+func (m watchModel) diagnostics() string {
+	var lines []string
+	for _, d := range m.last.Diagnostics {
+		if d.IsError() {
+			lines = append(lines, watchErrorStyle.Render("  ✗ "+d.String()))
+		}
+	}
+	for _, d := range m.last.Diagnostics {
+		if !d.IsError() {
+			lines = append(lines, watchWarningStyle.Render("  ⚠ "+d.String()))
+		}
+	}
+	if len(m.last.Diagnostics) == 0 {
+		for _, e := range m.last.Errors {
+			lines = append(lines, watchErrorStyle.Render("  ✗ "+e))
+		}
+	}
+	if len(lines) == 0 {
+		return "  No diagnostics.\n"
+	}
+
+	// Header, status and footer take about ten rows
+	if limit := m.height - 10; m.height > 0 && limit > 0 && len(lines) > limit {
+		hidden := len(lines) - limit
+		lines = append(lines[:limit], descStyle.Render(fmt.Sprintf("... %d more", hidden)))
+	}
+
+	header := fmt.Sprintf("  Diagnostics: %d error(s), %d warning(s)\n", len(m.last.Errors), len(m.last.Warnings))
+	return header + strings.Join(lines, "\n") + "\n"
+}
+
+// WatchDashboard runs watch mode as a full-screen view that redraws the
+// current diagnostics in place after every build
+func WatchDashboard(target string) error {
+	w, err := compiler.NewWatcher(target, compiler.ProfileAuto)
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events := make(chan compiler.WatchEvent)
+	go w.Run(ctx, events)
+
+	p := tea.NewProgram(watchModel{watcher: w, events: events}, tea.WithAltScreen())
+	_, err = p.Run()
+	return err
+}